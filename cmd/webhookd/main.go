@@ -0,0 +1,159 @@
+// Package main - webhookd hosts the webhook endpoints of several providers
+// and forwards the verified, normalized events to the configured sinks.
+//
+// Usage:
+//
+//	webhookd -config webhookd.json
+//
+// The config file is described in package config. Every event is written as one JSON object
+// with the fields provider, id, type, created and raw. If a sink fails, the request is answered
+// with 500 so that the provider retries the delivery; the other sinks then receive the event
+// again, so deduplicate by provider and id downstream.
+//
+// GET /healthz reports whether the process is alive, GET /readyz whether it accepts
+// events; it fails as soon as a shutdown starts.
+//
+// SIGHUP reloads the config file. The new endpoints and sinks take over new requests,
+// in-flight requests finish on the previous ones. An invalid config is logged and
+// the previous one stays active. Changes of listen and shutdown_timeout require a restart.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pilinux/webhook/config"
+)
+
+// health endpoints
+const (
+	healthPath = "/healthz"
+	readyPath  = "/readyz"
+)
+
+func main() {
+	configPath := flag.String("config", "webhookd.json", "path of the config file")
+	flag.Parse()
+
+	if err := run(*configPath); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(configPath string) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	// shut down gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	svc, err := cfg.BuildContext(ctx, logError)
+	if err != nil {
+		return err
+	}
+
+	// the current service, replaced on reload
+	var current atomic.Pointer[config.Service]
+	current.Store(svc)
+	defer func() {
+		if err := current.Load().Close(); err != nil {
+			log.Println("close sinks:", err)
+		}
+	}()
+
+	var ready atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+healthPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET "+readyPath, func(w http.ResponseWriter, _ *http.Request) {
+		if !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		current.Load().ServeHTTP(w, r)
+	})
+
+	srv := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// reload the config on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Println("listening on", cfg.Listen)
+		errCh <- srv.ListenAndServe()
+	}()
+	ready.Store(true)
+
+loop:
+	for {
+		select {
+		case err = <-errCh:
+			return err
+		case <-hup:
+			reload(ctx, configPath, &current)
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	log.Println("shutting down")
+	ready.Store(false)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+	if err = srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err = <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// reload builds a service from the config file and replaces the current one,
+// which is closed after its in-flight requests have finished.
+// A shutdown signal cancels reading the secrets.
+func reload(ctx context.Context, configPath string, current *atomic.Pointer[config.Service]) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Println("reload:", err)
+		return
+	}
+	svc, err := cfg.BuildContext(ctx, logError)
+	if err != nil {
+		log.Println("reload:", err)
+		return
+	}
+
+	old := current.Swap(svc)
+	log.Println("reloaded", configPath)
+	go func() {
+		if err := old.Close(); err != nil {
+			log.Println("close sinks:", err)
+		}
+	}()
+}
+
+func logError(r *http.Request, err error) {
+	log.Printf("%s: %v", r.URL.Path, err)
+}
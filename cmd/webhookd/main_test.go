@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook/config"
+	"github.com/pilinux/webhook/svixgo"
+)
+
+const testSecret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+
+// writeConfig writes a config with a resend endpoint at path and a file sink.
+func writeConfig(t *testing.T, configPath, path, sinkPath string) {
+	t.Helper()
+	data := `{
+		"endpoints": [{"path": "` + path + `", "provider": "resend", "secret": {"env": "WEBHOOKD_TEST_SECRET"}}],
+		"sinks": [{"type": "file", "path": "` + sinkPath + `"}]
+	}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// deliver posts a signed resend payload to svc and returns the status code.
+func deliver(t *testing.T, svc *config.Service, path, msgID string) int {
+	t.Helper()
+	r, err := svixgo.NewSignedRequest(context.Background(), testSecret, path, msgID, time.Now(),
+		[]byte(`{"type":"email.sent","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	svc.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestReload(t *testing.T) {
+	t.Setenv("WEBHOOKD_TEST_SECRET", testSecret)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "webhookd.json")
+	sinkPath := filepath.Join(dir, "events.jsonl")
+
+	writeConfig(t, configPath, "/hooks/resend", sinkPath)
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, err := cfg.Build(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var current atomic.Pointer[config.Service]
+	current.Store(svc)
+	defer func() { _ = current.Load().Close() }()
+
+	if code := deliver(t, current.Load(), "/hooks/resend", "msg_1"); code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", code)
+	}
+
+	// the endpoint moves on reload
+	writeConfig(t, configPath, "/resend", sinkPath)
+	reload(context.Background(), configPath, &current)
+	if current.Load() == svc {
+		t.Fatal("the service was not replaced")
+	}
+	if code := deliver(t, current.Load(), "/hooks/resend", "msg_2"); code != http.StatusNotFound {
+		t.Errorf("old path: got status %d, want 404", code)
+	}
+	if code := deliver(t, current.Load(), "/resend", "msg_3"); code != http.StatusOK {
+		t.Errorf("new path: got status %d, want 200", code)
+	}
+
+	// an invalid config keeps the current service
+	reloaded := current.Load()
+	writeConfig(t, configPath, "/a//b", sinkPath)
+	reload(context.Background(), configPath, &current)
+	if current.Load() != reloaded {
+		t.Error("an invalid config replaced the service")
+	}
+
+	// both services appended to the same file
+	b, err := os.ReadFile(sinkPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(b), "\n") != 2 || !strings.Contains(string(b), `"id":"msg_1"`) || !strings.Contains(string(b), `"id":"msg_3"`) {
+		t.Errorf("got events:\n%s", b)
+	}
+}
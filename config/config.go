@@ -0,0 +1,314 @@
+// Package config describes a webhook service declaratively: its endpoints, their provider,
+// secret source, allowed event types and body limit, and the sinks events are routed to.
+//
+// The config file is JSON:
+//
+/*
+	{
+		"listen": ":8080",
+		"shutdown_timeout": "10s",
+		"endpoints": [
+			{
+				"path": "/stripe",
+				"provider": "stripe",
+				"secret": {"env": "STRIPE_WEBHOOK_SECRET"},
+				"events": ["invoice.*", "customer.subscription.*"],
+				"sinks": ["billing"]
+			},
+			{
+				"path": "/resend",
+				"provider": "resend",
+				"secret": {"file": "/run/secrets/resend_webhook_secret"},
+				"max_body_bytes": 131072
+			},
+			{
+				"path": "/resend-marketing",
+				"provider": "resend",
+				"secret": {"vault": {"address": "http://127.0.0.1:8200", "path": "secret/data/webhooks", "field": "resend"}}
+			}
+		],
+		"sinks": [
+			{"name": "log", "type": "stdout"},
+			{"name": "archive", "type": "file", "path": "events.jsonl"},
+			{"name": "billing", "type": "http", "url": "http://localhost:9000/events", "timeout": "5s"}
+		]
+	}
+*/
+//
+// An endpoint without events accepts all event types; a pattern ending in `*` matches
+// all event types with that prefix. Events of other types are acknowledged and dropped.
+// An endpoint without sinks routes its events to all sinks.
+//
+// Secrets are never part of the config file. File secrets are polled for changes and
+// Vault secrets are cached for a minute, so both rotate without a restart or reload.
+// The Vault token is read from the environment variable token_env, `VAULT_TOKEN` if not set.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+	"github.com/pilinux/webhook/stripe"
+)
+
+// defaults
+const (
+	DefaultListen          = ":8080"
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+// reserved paths of the health endpoints
+var reservedPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// Config struct of a webhook service
+type Config struct {
+	// Listen is the address of the HTTP server, e.g. `:8080`
+	Listen string `json:"listen"`
+	// ShutdownTimeout is the time to finish in-flight requests on shutdown, e.g. `10s`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+
+	Endpoints []Endpoint `json:"endpoints"`
+	Sinks     []Sink     `json:"sinks"`
+}
+
+// Endpoint struct
+type Endpoint struct {
+	// Path of the endpoint, e.g. `/stripe`
+	Path string `json:"path"`
+	// Provider is `stripe` or `resend`
+	Provider string `json:"provider"`
+	// Secret is the source of the webhook signing secret
+	Secret Secret `json:"secret"`
+	// Events are the allowed event types, all if empty
+	Events []string `json:"events,omitempty"`
+	// MaxBodyBytes is the maximum size of a request body, 64KB if not set
+	MaxBodyBytes int64 `json:"max_body_bytes,omitempty"`
+	// Sinks are the names of the sinks events are routed to, all if empty
+	Sinks []string `json:"sinks,omitempty"`
+}
+
+// Secret is the source of a secret: an environment variable, a file or Vault.
+type Secret struct {
+	Env   string       `json:"env,omitempty"`
+	File  string       `json:"file,omitempty"`
+	Vault *VaultSecret `json:"vault,omitempty"`
+}
+
+// VaultSecret struct
+type VaultSecret struct {
+	// Address is the base URL of the server, e.g. `http://127.0.0.1:8200`
+	Address string `json:"address"`
+	// TokenEnv is the environment variable holding the token, `VAULT_TOKEN` if not set
+	TokenEnv string `json:"token_env,omitempty"`
+	// Path of the secret, e.g. `secret/data/webhooks`
+	Path string `json:"path"`
+	// Field of the secret holding the webhook signing secret
+	Field string `json:"field"`
+	// TTL is the time the secret is cached, 1m if not set
+	TTL Duration `json:"ttl,omitempty"`
+}
+
+// Sink struct
+type Sink struct {
+	// Name is referenced by endpoints, defaults to the type
+	Name string `json:"name"`
+	// Type is `stdout`, `file` or `http`
+	Type string `json:"type"`
+	// Path of the JSON Lines file for file sinks
+	Path string `json:"path,omitempty"`
+	// URL events are posted to for http sinks
+	URL string `json:"url,omitempty"`
+	// Timeout of a request for http sinks, 10s if not set
+	Timeout Duration `json:"timeout,omitempty"`
+}
+
+// Duration is a time.Duration written as a string in JSON, e.g. `10s`.
+type Duration time.Duration
+
+// UnmarshalJSON parses the duration string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string, e.g. \"10s\": %s", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON writes the duration string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Load reads, parses and validates the config file.
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse parses and validates the JSON config and sets the defaults.
+// Unknown fields are rejected to catch typos.
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, col := position(data, syntaxErr.Offset)
+			return nil, fmt.Errorf("line %d, column %d: %w", line, col, err)
+		}
+		return nil, err
+	}
+
+	cfg.setDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// position returns the line and column of the byte offset.
+func position(data []byte, offset int64) (line, col int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = int(offset) - bytes.LastIndexByte(before, '\n')
+	return
+}
+
+func (cfg *Config) setDefaults() {
+	if cfg.Listen == "" {
+		cfg.Listen = DefaultListen
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = Duration(DefaultShutdownTimeout)
+	}
+	for i := range cfg.Sinks {
+		if cfg.Sinks[i].Name == "" {
+			cfg.Sinks[i].Name = cfg.Sinks[i].Type
+		}
+	}
+}
+
+// Validate checks the config for missing, unknown and conflicting values
+// and reports all problems at once.
+func (cfg *Config) Validate() error {
+	var errs []error
+	add := func(format string, a ...any) {
+		errs = append(errs, fmt.Errorf(format, a...))
+	}
+
+	sinks := make(map[string]bool)
+	if len(cfg.Sinks) == 0 {
+		add("sinks: at least one sink is required")
+	}
+	for i, s := range cfg.Sinks {
+		if sinks[s.Name] {
+			add("sinks[%d]: duplicate name %q", i, s.Name)
+		}
+		sinks[s.Name] = true
+
+		switch s.Type {
+		case "stdout":
+		case "file":
+			if s.Path == "" {
+				add("sinks[%d] (%s): path is required for file sinks", i, s.Name)
+			}
+		case "http":
+			if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+				add("sinks[%d] (%s): url must start with http:// or https://: %q", i, s.Name, s.URL)
+			}
+		default:
+			add("sinks[%d]: unknown type %q, must be stdout, file or http", i, s.Type)
+		}
+	}
+
+	paths := make(map[string]bool)
+	if len(cfg.Endpoints) == 0 {
+		add("endpoints: at least one endpoint is required")
+	}
+	for i, e := range cfg.Endpoints {
+		if !strings.HasPrefix(e.Path, "/") {
+			add("endpoints[%d]: path must start with /: %q", i, e.Path)
+		}
+		// paths are literal, wildcards and whitespace would make http.ServeMux panic
+		if strings.ContainsAny(e.Path, "{} \t\r\n") {
+			add("endpoints[%d]: path must not contain braces or whitespace: %q", i, e.Path)
+		}
+		// http.ServeMux panics on paths that can never match, e.g. /a/../b or /a//b
+		if e.Path != "/" && path.Clean(e.Path) != strings.TrimSuffix(e.Path, "/") {
+			add("endpoints[%d]: path must be clean: %q", i, e.Path)
+		}
+		if reservedPaths[e.Path] {
+			add("endpoints[%d]: path %s is reserved for health checks", i, e.Path)
+		}
+		if paths[e.Path] {
+			add("endpoints[%d]: duplicate path %s", i, e.Path)
+		}
+		paths[e.Path] = true
+
+		switch e.Provider {
+		case stripe.ProviderName:
+		case resend.ProviderName:
+			for _, t := range e.Events {
+				if !strings.HasSuffix(t, "*") && !resend.EventType(t).Valid() {
+					add("endpoints[%d] (%s): unknown resend event type %q", i, e.Path, t)
+				}
+			}
+		default:
+			add("endpoints[%d] (%s): unknown provider %q, must be stripe or resend", i, e.Path, e.Provider)
+		}
+
+		sources := 0
+		for _, set := range []bool{e.Secret.Env != "", e.Secret.File != "", e.Secret.Vault != nil} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
+			add("endpoints[%d] (%s): secret must have exactly one of env, file or vault", i, e.Path)
+		}
+		if v := e.Secret.Vault; v != nil {
+			if !strings.HasPrefix(v.Address, "http://") && !strings.HasPrefix(v.Address, "https://") {
+				add("endpoints[%d] (%s): vault address must start with http:// or https://: %q", i, e.Path, v.Address)
+			}
+			if v.Path == "" || v.Field == "" {
+				add("endpoints[%d] (%s): vault path and field are required", i, e.Path)
+			}
+		}
+		if e.MaxBodyBytes < 0 {
+			add("endpoints[%d] (%s): max_body_bytes must not be negative", i, e.Path)
+		}
+		for _, name := range e.Sinks {
+			if !sinks[name] {
+				add("endpoints[%d] (%s): unknown sink %q", i, e.Path, name)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRejectsPathPatterns(t *testing.T) {
+	tests := map[string]string{
+		"/a/{x":            "braces or whitespace",
+		"/a/{x}":           "braces or whitespace",
+		"/a b":             "braces or whitespace",
+		"/a/{$}":           "braces or whitespace",
+		"/hooks/../stripe": "must be clean",
+		"/a//b":            "must be clean",
+		"/a/./b":           "must be clean",
+		"/a/.":             "must be clean",
+		"/a//":             "must be clean",
+	}
+	for path, want := range tests {
+		data := `{"endpoints":[{"path":"` + path + `","provider":"stripe","secret":{"env":"X"}}],"sinks":[{"type":"stdout"}]}`
+		_, err := Parse([]byte(data))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: got %v, want path error %q", path, err, want)
+		}
+	}
+}
+
+func TestBuildLiteralPaths(t *testing.T) {
+	t.Setenv("WEBHOOK_SECRET", "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw")
+	cfg, err := Parse([]byte(`{
+		"endpoints": [
+			{"path": "/a", "provider": "resend", "secret": {"env": "WEBHOOK_SECRET"}},
+			{"path": "/a/", "provider": "stripe", "secret": {"env": "WEBHOOK_SECRET"}},
+			{"path": "/a/b.c-d_e", "provider": "stripe", "secret": {"env": "WEBHOOK_SECRET"}}
+		],
+		"sinks": [{"type": "stdout"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc, err := cfg.Build(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = svc.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/resend"
+	"github.com/pilinux/webhook/secret"
+	"github.com/pilinux/webhook/sink"
+	"github.com/pilinux/webhook/stripe"
+)
+
+// Service is the http.Handler of all endpoints of a config.
+//
+// Close waits for in-flight requests before closing the sinks, so that a reloaded
+// config can replace the service without dropping requests. Requests arriving after
+// Close are answered with 503 Service Unavailable, so that the provider retries them.
+type Service struct {
+	mux     *http.ServeMux
+	sinks   sink.Multi
+	closers []io.Closer
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// secretTimeout bounds reading the secrets of all endpoints in Build.
+const secretTimeout = 10 * time.Second
+
+// Build creates the sinks and the handlers of all endpoints.
+// onError is called with every rejected request and every sink error, it may be nil.
+func (cfg *Config) Build(onError func(r *http.Request, err error)) (*Service, error) {
+	return cfg.BuildContext(context.Background(), onError)
+}
+
+// BuildContext is Build with a context that cancels reading the secrets,
+// which is bounded by a timeout of 10 seconds in any case.
+func (cfg *Config) BuildContext(ctx context.Context, onError func(r *http.Request, err error)) (*Service, error) {
+	s := &Service{mux: http.NewServeMux()}
+	ctx, cancel := context.WithTimeout(ctx, secretTimeout)
+	defer cancel()
+
+	sinks := make(map[string]sink.Sink)
+	for _, c := range cfg.Sinks {
+		sk, err := newSink(c)
+		if err != nil {
+			_ = s.close()
+			return nil, fmt.Errorf("sink %s: %w", c.Name, err)
+		}
+		sinks[c.Name] = sk
+		s.sinks = append(s.sinks, sk)
+	}
+
+	for _, e := range cfg.Endpoints {
+		p, err := s.newProvider(ctx, e)
+		if err != nil {
+			_ = s.close()
+			return nil, fmt.Errorf("endpoint %s: %w", e.Path, err)
+		}
+
+		out := s.sinks
+		if len(e.Sinks) > 0 {
+			out = nil
+			for _, name := range e.Sinks {
+				out = append(out, sinks[name])
+			}
+		}
+
+		h := webhook.NewHandler(p, out.Write)
+		if len(e.Events) > 0 {
+			h.Use(allowEvents(e.Events))
+		}
+		if onError != nil {
+			h.OnError(onError)
+		}
+		s.mux.Handle("POST "+e.Path, h)
+	}
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close waits for in-flight requests and closes the sinks.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.close()
+}
+
+// close stops the secret providers and closes the sinks.
+func (s *Service) close() error {
+	errs := []error{s.sinks.Close()}
+	for _, c := range s.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// newSink creates the sink described by the config.
+func newSink(c Sink) (sink.Sink, error) {
+	switch c.Type {
+	case "stdout":
+		return sink.NewWriter(os.Stdout), nil
+	case "file":
+		return sink.NewFile(c.Path)
+	case "http":
+		return sink.NewHTTP(c.URL, time.Duration(c.Timeout)), nil
+	}
+	return nil, fmt.Errorf("unknown sink type: %q", c.Type)
+}
+
+// newSecret creates the secret provider of the endpoint.
+func (s *Service) newSecret(e Endpoint) (webhook.SecretProvider, error) {
+	switch {
+	case e.Secret.Env != "":
+		return secret.Env(e.Secret.Env), nil
+
+	case e.Secret.File != "":
+		f, err := secret.NewFile(e.Secret.File, 0)
+		if err != nil {
+			return nil, err
+		}
+		s.closers = append(s.closers, f)
+		return f, nil
+
+	case e.Secret.Vault != nil:
+		c := e.Secret.Vault
+		tokenEnv := c.TokenEnv
+		if tokenEnv == "" {
+			tokenEnv = "VAULT_TOKEN"
+		}
+		token := strings.TrimSpace(os.Getenv(tokenEnv))
+		if token == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", tokenEnv)
+		}
+		v := secret.NewVault(c.Address, token, c.Path, c.Field)
+		v.TTL = time.Duration(c.TTL)
+		return v, nil
+	}
+	return nil, errors.New("no secret source")
+}
+
+// newProvider creates the webhook provider of the endpoint.
+func (s *Service) newProvider(ctx context.Context, e Endpoint) (webhook.Provider, error) {
+	sp, err := s.newSecret(e)
+	if err != nil {
+		return nil, err
+	}
+	// fail fast on a missing secret
+	if _, err = sp.Secret(ctx); err != nil {
+		return nil, err
+	}
+
+	switch e.Provider {
+	case stripe.ProviderName:
+		p := stripe.NewProviderWithSecret(sp)
+		p.MaxBodyBytes = e.MaxBodyBytes
+		return p, nil
+
+	case resend.ProviderName:
+		var opts []resend.Option
+		if e.MaxBodyBytes > 0 {
+			opts = append(opts, resend.WithMaxBodyBytes(e.MaxBodyBytes))
+		}
+		return resend.NewProviderWithSecret(sp, opts...), nil
+	}
+	return nil, fmt.Errorf("unknown provider: %q", e.Provider)
+}
+
+// allowEvents acknowledges events of types that match none of the patterns without forwarding them.
+func allowEvents(patterns []string) webhook.Middleware {
+	return func(next webhook.HandlerFunc) webhook.HandlerFunc {
+		return func(ctx context.Context, event webhook.Event) error {
+			for _, p := range patterns {
+				if p == event.Type || (strings.HasSuffix(p, "*") && strings.HasPrefix(event.Type, strings.TrimSuffix(p, "*"))) {
+					return next(ctx, event)
+				}
+			}
+			return nil
+		}
+	}
+}
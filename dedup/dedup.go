@@ -0,0 +1,114 @@
+// Package dedup remembers the IDs of processed events so that events
+// delivered more than once are handled only once.
+//
+// Use the same store and Middleware for every path that feeds a handler,
+// e.g. the webhook endpoint and stripe.Reconciler:
+//
+/*
+	store := dedup.NewMemoryStore(72 * time.Hour)
+
+	h := webhook.NewHandler(stripe.NewProvider(secret), handle).Use(dedup.Middleware(store))
+	r := stripe.NewReconciler(key, "", store, handle)
+*/
+package dedup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook"
+)
+
+// Store keeps track of processed event IDs.
+type Store interface {
+	// Add records the ID atomically. It reports false if the ID is already
+	// recorded and has not expired yet.
+	Add(ctx context.Context, id string) (added bool, err error)
+	// Remove forgets the ID, so that the event can be processed again
+	Remove(ctx context.Context, id string) error
+}
+
+// MemoryStore is an in-memory Store safe for concurrent use.
+type MemoryStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	seen  map[string]time.Time
+	swept time.Time
+}
+
+// NewMemoryStore creates a new in-memory store that forgets IDs after ttl.
+// A ttl of zero keeps IDs forever.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:  ttl,
+		now:  time.Now,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// Add records the ID unless it is recorded and has not expired yet.
+// Expired IDs are removed once per ttl.
+func (s *MemoryStore) Add(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.ttl > 0 && now.Sub(s.swept) > s.ttl {
+		s.swept = now
+		for k, added := range s.seen {
+			if now.Sub(added) > s.ttl {
+				delete(s.seen, k)
+			}
+		}
+	}
+
+	if added, ok := s.seen[id]; ok && (s.ttl == 0 || now.Sub(added) <= s.ttl) {
+		return false, nil
+	}
+	s.seen[id] = now
+	return true, nil
+}
+
+// Remove forgets the ID.
+func (s *MemoryStore) Remove(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.seen, id)
+	return nil
+}
+
+// Key returns the ID of the event in the store, qualified by its provider.
+func Key(event webhook.Event) string {
+	return event.Provider + ":" + event.ID
+}
+
+// Middleware passes every event on once. Events whose key is already in the store
+// are acknowledged without calling next; if next fails, the key is removed again,
+// so that a redelivery is processed.
+func Middleware(store Store) webhook.Middleware {
+	return func(next webhook.HandlerFunc) webhook.HandlerFunc {
+		return func(ctx context.Context, event webhook.Event) error {
+			key := Key(event)
+			added, err := store.Add(ctx, key)
+			if err != nil {
+				return err
+			}
+			if !added {
+				return nil
+			}
+
+			if err = next(ctx, event); err != nil {
+				if rerr := store.Remove(ctx, key); rerr != nil {
+					return errors.Join(err, rerr)
+				}
+				return err
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook"
+)
+
+func TestMemoryStoreAdd(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore(time.Minute)
+	s.now = func() time.Time { return now }
+
+	add := func(id string, want bool) {
+		t.Helper()
+		added, err := s.Add(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if added != want {
+			t.Errorf("Add(%s) = %v, want %v", id, added, want)
+		}
+	}
+
+	add("evt_1", true)
+	add("evt_1", false)
+	add("evt_2", true)
+
+	if err := s.Remove(ctx, "evt_2"); err != nil {
+		t.Fatal(err)
+	}
+	add("evt_2", true)
+
+	// IDs expire after the ttl
+	now = now.Add(time.Minute)
+	add("evt_1", false)
+	now = now.Add(time.Second)
+	add("evt_1", true)
+	if len(s.seen) != 1 {
+		t.Errorf("got %d stored IDs, want the expired evt_2 swept", len(s.seen))
+	}
+}
+
+func TestMemoryStoreWithoutTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore(0)
+	s.now = func() time.Time { return now }
+
+	if added, _ := s.Add(ctx, "evt_1"); !added {
+		t.Fatal("evt_1 was not added")
+	}
+	now = now.Add(365 * 24 * time.Hour)
+	if added, _ := s.Add(ctx, "evt_1"); added {
+		t.Error("evt_1 expired without a ttl")
+	}
+}
+
+func TestMemoryStoreAddIsAtomic(t *testing.T) {
+	s := NewMemoryStore(time.Minute)
+
+	var added atomic.Int32
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := s.Add(context.Background(), "evt_1"); ok {
+				added.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := added.Load(); n != 1 {
+		t.Errorf("added %d times, want 1", n)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(time.Minute)
+
+	calls := 0
+	fail := true
+	fn := Middleware(s)(func(context.Context, webhook.Event) error {
+		calls++
+		if fail {
+			fail = false
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	event := webhook.Event{Provider: "stripe", ID: "evt_1"}
+	// the failed event is removed, so that the redelivery is processed
+	if err := fn(ctx, event); err == nil {
+		t.Fatal("got nil error from the failing handler")
+	}
+	if err := fn(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	if err := fn(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	// IDs are qualified by provider
+	if err := fn(ctx, webhook.Event{Provider: "resend", ID: "evt_1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+}
@@ -0,0 +1,167 @@
+// Package dispatch processes webhook events concurrently while keeping
+// events that belong to the same entity in order.
+//
+// Every event is routed to one of a fixed number of workers by a key extracted
+// from the event, e.g. the Stripe customer ID or the Resend email ID.
+// Events with the same key always land on the same worker and are processed
+// serially; events with different keys run in parallel.
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by Submit after the dispatcher has been closed.
+var ErrClosed = errors.New("dispatch: dispatcher is closed")
+
+// KeyFunc extracts the ordering key from an event.
+// Events with an empty key have no ordering constraint.
+type KeyFunc[T any] func(event T) string
+
+// HandlerFunc processes a single event.
+type HandlerFunc[T any] func(ctx context.Context, event T) error
+
+type job[T any] struct {
+	ctx   context.Context
+	event T
+}
+
+// Dispatcher routes events to workers by key.
+type Dispatcher[T any] struct {
+	key     KeyFunc[T]
+	handler HandlerFunc[T]
+	onError func(event T, err error)
+
+	queues []chan job[T]
+	next   atomic.Uint64
+	// closing rejects new events at once, while Close waits for blocked Submit calls
+	closing atomic.Bool
+	mu      sync.RWMutex
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// New creates a new dispatcher and starts its workers.
+// queueSize is the number of events each worker buffers before Submit blocks.
+func New[T any](workers, queueSize int, key KeyFunc[T], handler HandlerFunc[T]) *Dispatcher[T] {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	d := &Dispatcher[T]{
+		key:     key,
+		handler: handler,
+		queues:  make([]chan job[T], workers),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan job[T], queueSize)
+		d.wg.Add(1)
+		go d.work(d.queues[i])
+	}
+	return d
+}
+
+// OnError sets the function called with every event whose handler returned an error
+// or panicked.
+// It must be set before the first call to Submit.
+func (d *Dispatcher[T]) OnError(fn func(event T, err error)) {
+	d.onError = fn
+}
+
+// Submit queues the event on the worker responsible for its key.
+// It blocks while that worker's queue is full, until ctx is done.
+//
+// The handler receives a context that carries the values of ctx
+// but is not canceled with it, so the event can outlive the HTTP request.
+func (d *Dispatcher[T]) Submit(ctx context.Context, event T) error {
+	if d.closing.Load() {
+		return ErrClosed
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return ErrClosed
+	}
+
+	queue := d.queues[d.shard(d.key(event))]
+	select {
+	case queue <- job[T]{ctx: context.WithoutCancel(ctx), event: event}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting new events and waits until all queued events are processed
+// or ctx is done.
+func (d *Dispatcher[T]) Close(ctx context.Context) error {
+	d.closing.Store(true)
+
+	done := make(chan struct{})
+	go func() {
+		// waits for Submit calls blocked on a full queue
+		d.mu.Lock()
+		if !d.closed {
+			d.closed = true
+			for _, queue := range d.queues {
+				close(queue)
+			}
+		}
+		d.mu.Unlock()
+
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shard returns the index of the worker for the given key.
+func (d *Dispatcher[T]) shard(key string) int {
+	n := uint64(len(d.queues))
+	if key == "" {
+		// no ordering constraint, spread the events evenly
+		return int(d.next.Add(1) % n)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int(h.Sum64() % n)
+}
+
+func (d *Dispatcher[T]) work(queue <-chan job[T]) {
+	defer d.wg.Done()
+
+	for j := range queue {
+		d.handle(j)
+	}
+}
+
+// handle runs the handler and passes its error or panic to onError,
+// so that a panicking handler does not stop the worker.
+func (d *Dispatcher[T]) handle(j job[T]) {
+	defer func() {
+		if p := recover(); p != nil && d.onError != nil {
+			d.onError(j.event, fmt.Errorf("dispatch: handler panicked: %v", p))
+		}
+	}()
+
+	if err := d.handler(j.ctx, j.event); err != nil && d.onError != nil {
+		d.onError(j.event, err)
+	}
+}
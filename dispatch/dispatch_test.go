@@ -0,0 +1,216 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type event struct {
+	key string
+	seq int
+}
+
+func eventKey(e event) string { return e.key }
+
+func TestDispatcherKeepsOrderPerKey(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	got := map[string][]int{}
+	d := New(4, 8, eventKey, func(_ context.Context, e event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got[e.key] = append(got[e.key], e.seq)
+		return nil
+	})
+
+	keys := []string{"cus_1", "cus_2", "cus_3", "cus_4", "cus_5"}
+	for seq := range 100 {
+		for _, key := range keys {
+			if err := d.Submit(ctx, event{key: key, seq: seq}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range keys {
+		seqs := got[key]
+		if len(seqs) != 100 {
+			t.Fatalf("%s: got %d events, want 100", key, len(seqs))
+		}
+		for i, seq := range seqs {
+			if seq != i {
+				t.Fatalf("%s: event %d has seq %d", key, i, seq)
+			}
+		}
+	}
+}
+
+func TestDispatcherCloseDrainsQueues(t *testing.T) {
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var handled int
+	d := New(2, 10, eventKey, func(_ context.Context, _ event) error {
+		<-release
+		mu.Lock()
+		handled++
+		mu.Unlock()
+		return nil
+	})
+
+	for seq := range 10 {
+		if err := d.Submit(ctx, event{seq: seq}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(release)
+
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if handled != 10 {
+		t.Errorf("handled %d events, want 10", handled)
+	}
+}
+
+func TestDispatcherSubmitAfterClose(t *testing.T) {
+	ctx := context.Background()
+	d := New(1, 1, eventKey, func(context.Context, event) error { return nil })
+
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Submit(ctx, event{key: "a"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Close = %v, want ErrClosed", err)
+	}
+	// closing twice is fine
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stuck returns a dispatcher with one worker blocked on its first event
+// and a full queue; the worker is released when release is closed.
+func stuck(t *testing.T) (d *Dispatcher[event], release chan struct{}) {
+	t.Helper()
+
+	release = make(chan struct{})
+	started := make(chan struct{}, 1)
+	d = New(1, 1, eventKey, func(context.Context, event) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := d.Submit(ctx, event{seq: 1}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := d.Submit(ctx, event{seq: 2}); err != nil {
+		t.Fatal(err)
+	}
+	return d, release
+}
+
+func TestDispatcherSubmitCanceledWhileQueueFull(t *testing.T) {
+	d, release := stuck(t)
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := d.Submit(ctx, event{seq: 3}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestDispatcherCloseHonorsDeadlineWhileSubmitBlocked(t *testing.T) {
+	d, release := stuck(t)
+
+	// blocks on the full queue while holding the read lock
+	submitted := make(chan error, 1)
+	go func() {
+		submitted <- d.Submit(context.Background(), event{seq: 3})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	closed := make(chan error, 1)
+	go func() { closed <- d.Close(ctx) }()
+
+	select {
+	case err := <-closed:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Close = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close ignored its deadline")
+	}
+
+	if err := d.Submit(context.Background(), event{seq: 4}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit while closing = %v, want ErrClosed", err)
+	}
+
+	close(release)
+	if err := <-submitted; err != nil {
+		t.Errorf("blocked Submit = %v", err)
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatcherRecoversPanics(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var errs []error
+	d := New(1, 4, eventKey, func(_ context.Context, e event) error {
+		switch e.seq {
+		case 1:
+			panic("boom")
+		case 2:
+			return errors.New("failed")
+		}
+		return nil
+	})
+	d.OnError(func(_ event, err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+
+	for seq := range 4 {
+		if err := d.Submit(ctx, event{seq: seq}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors, want 2: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Error(), "panicked: boom") {
+		t.Errorf("errs[0] = %v, want the recovered panic", errs[0])
+	}
+	if errs[1].Error() != "failed" {
+		t.Errorf("errs[1] = %v, want failed", errs[1])
+	}
+}
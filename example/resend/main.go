@@ -2,104 +2,147 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"strings"
 	"time"
 
 	"github.com/pilinux/webhook/resend"
-	"github.com/pilinux/webhook/svixgo"
+	"github.com/pilinux/webhook/secret"
 )
 
 func main() {
-	// load the webhook secret from the environment
-	secret := os.Getenv("WEBHOOK_SECRET")
-	secret = strings.TrimSpace(secret)
-	if secret == "" {
-		fmt.Println("missing webhook secret")
+	// read the webhook secret from the environment for every request,
+	// use secret.NewFile or secret.NewVault to rotate it without a restart
+	sp := secret.Env("WEBHOOK_SECRET")
+	if _, err := sp.Secret(context.Background()); err != nil {
+		fmt.Println("missing webhook secret:", err)
 		return
 	}
 
-	// create a new webhook instance
-	wh, err := svixgo.NewWebhook(secret)
-	if err != nil {
-		fmt.Println("error creating webhook instance:", err)
-		return
-	}
-
-	// handle incoming request
-	http.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
-		// print the request method
-		fmt.Println("=====================================")
-		fmt.Println("time:", time.Now().Format(time.RFC3339))
-		fmt.Println("method:", r.Method)
-
-		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			return
-		}
-
-		// process the incoming request
-		payload, err := resend.HandleRequest(r, wh)
-		if err != nil {
-			fmt.Println("error processing request:", err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+	// create a handler which verifies the requests and dispatches the payloads by event type
+	h := resend.NewHandlerWithSecret(sp)
+	h.OnError(func(_ *http.Request, err error) {
+		fmt.Println("error processing request:", err)
+	})
 
-		// do something with the payload
-		fmt.Println("event type:", payload.Type)
-		fmt.Println("created at:", payload.CreatedAt)
-		t, err := time.Parse(time.RFC3339, payload.CreatedAt)
-		if err == nil {
-			fmt.Println("create at (Unix epoch timestamp):", t.Unix())
+	// print the common fields of every payload
+	h.Use(func(next resend.HandlerFunc) resend.HandlerFunc {
+		return func(ctx context.Context, payload resend.Payload) error {
+			fmt.Println("=====================================")
+			fmt.Println("time:", time.Now().Format(time.RFC3339))
+			fmt.Println("event type:", payload.Type)
+			fmt.Println("created at:", payload.CreatedAt.Format(time.RFC3339))
+			fmt.Println("create at (Unix epoch timestamp):", payload.CreatedAt.Unix())
+			err := next(ctx, payload)
+			fmt.Println("=====================================")
+			return err
 		}
+	})
 
-		// for email events
-		if payload.Type == resend.EmailSent ||
-			payload.Type == resend.EmailDelivered ||
-			payload.Type == resend.EmailDeliveryDelayed ||
-			payload.Type == resend.EmailComplained ||
-			payload.Type == resend.EmailBounced ||
-			payload.Type == resend.EmailOpened ||
-			payload.Type == resend.EmailClicked {
-			fmt.Println("email id:", payload.Data.EmailID)
-			fmt.Println("from:", payload.Data.From)
-			fmt.Println("to:", payload.Data.To)
-			fmt.Println("subject:", payload.Data.Subject)
-			if payload.Data.Click != nil {
-				fmt.Println("click:")
-				fmt.Println("  ip address:", payload.Data.Click.IPAddress)
-				fmt.Println("  link:", payload.Data.Click.Link)
-				fmt.Println("  timestamp:", payload.Data.Click.Timestamp)
-				fmt.Println("  user agent:", payload.Data.Click.UserAgent)
-			}
-		}
+	// for email events
+	h.OnEmailSent(printEmail)
+	h.OnEmailDelivered(printEmail)
+	h.OnEmailDeliveryDelayed(printEmail)
+	h.OnEmailComplained(printEmail)
+	h.OnEmailBounced(printEmail)
+	h.OnEmailOpened(printEmail)
+	h.OnEmailClicked(printEmail)
+	h.OnEmailFailed(printEmail)
+	h.OnEmailScheduled(printEmail)
+	h.OnEmailSuppressed(printEmail)
 
-		// for contact events
-		if payload.Type == resend.ContactCreated ||
-			payload.Type == resend.ContactUpdated ||
-			payload.Type == resend.ContactDeleted {
-			fmt.Println("contact id:", payload.Data.ID)
-			fmt.Println("audience id:", payload.Data.AudienceID)
-			fmt.Println("email:", payload.Data.Email)
-			fmt.Println("first name:", payload.Data.FirstName)
-			fmt.Println("last name:", payload.Data.LastName)
-			fmt.Println("unsubscribed:", payload.Data.Unsubscribed)
-		}
+	// for contact events
+	h.OnContactCreated(printContact)
+	h.OnContactUpdated(printContact)
+	h.OnContactDeleted(printContact)
 
-		fmt.Println("=====================================")
+	// for domain events
+	h.OnDomainCreated(printDomain)
+	h.OnDomainUpdated(printDomain)
+	h.OnDomainDeleted(printDomain)
 
-		// send a response
-		w.WriteHeader(http.StatusOK)
+	// for event types unknown to this version of the package
+	h.Fallback(func(_ context.Context, payload resend.Payload) error {
+		fmt.Println("unknown event type")
+		fmt.Println("data:", string(payload.Data.Raw))
+		return nil
 	})
 
+	// handle incoming request
+	http.Handle("/webhook", h)
+
 	// start the server
 	fmt.Println("starting server at:", time.Now().Format(time.RFC3339))
-	err = http.ListenAndServe(":8080", nil)
+	err := http.ListenAndServe(":8080", nil)
 	if err != nil {
 		fmt.Println("error starting server:", err)
 		return
 	}
 }
+
+func printEmail(_ context.Context, payload resend.Payload) error {
+	email, _ := payload.AsEmailEvent()
+	fmt.Println("email id:", email.EmailID)
+	fmt.Println("from:", email.From)
+	fmt.Println("to:", email.To)
+	fmt.Println("subject:", email.Subject)
+	if len(email.Tags) > 0 {
+		fmt.Println("tags:", email.Tags)
+	}
+	if email.Bounce != nil {
+		fmt.Println("bounce:")
+		fmt.Println("  type:", email.Bounce.Type)
+		fmt.Println("  sub type:", email.Bounce.SubType)
+		fmt.Println("  message:", email.Bounce.Message)
+		fmt.Println("  hard bounce:", email.Bounce.IsHard())
+	}
+	if email.Open != nil {
+		fmt.Println("open:")
+		fmt.Println("  ip address:", email.Open.IPAddress)
+		fmt.Println("  timestamp:", email.Open.Timestamp.Format(time.RFC3339))
+		fmt.Println("  user agent:", email.Open.UserAgent)
+	}
+	if email.Click != nil {
+		fmt.Println("click:")
+		fmt.Println("  ip address:", email.Click.IPAddress)
+		fmt.Println("  link:", email.Click.Link)
+		fmt.Println("  timestamp:", email.Click.Timestamp.Format(time.RFC3339))
+		fmt.Println("  user agent:", email.Click.UserAgent)
+	}
+	if email.Failed != nil {
+		fmt.Println("failure reason:", email.Failed.Reason)
+	}
+	if !email.ScheduledAt.IsZero() {
+		fmt.Println("scheduled at:", email.ScheduledAt)
+	}
+	if email.Suppressed != nil {
+		fmt.Println("suppressed:")
+		fmt.Println("  type:", email.Suppressed.Type)
+		fmt.Println("  message:", email.Suppressed.Message)
+	}
+	return nil
+}
+
+func printContact(_ context.Context, payload resend.Payload) error {
+	contact, _ := payload.AsContactEvent()
+	fmt.Println("contact id:", contact.ID)
+	fmt.Println("audience id:", contact.AudienceID)
+	fmt.Println("email:", contact.Email)
+	fmt.Println("first name:", contact.FirstName)
+	fmt.Println("last name:", contact.LastName)
+	fmt.Println("unsubscribed:", contact.Unsubscribed)
+	return nil
+}
+
+func printDomain(_ context.Context, payload resend.Payload) error {
+	domain, _ := payload.AsDomainEvent()
+	fmt.Println("domain id:", domain.ID)
+	fmt.Println("name:", domain.Name)
+	fmt.Println("status:", domain.Status)
+	fmt.Println("region:", domain.Region)
+	for _, record := range domain.Records {
+		fmt.Printf("record: %s %s %s (%s)\n", record.Record, record.Type, record.Name, record.Status)
+	}
+	return nil
+}
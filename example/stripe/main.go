@@ -2,22 +2,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/pilinux/webhook/secret"
 	wh "github.com/pilinux/webhook/stripe"
 	"github.com/stripe/stripe-go/v79"
 )
 
 func main() {
-	// load the webhook secret from the environment
-	secret := os.Getenv("STRIPE_WEBHOOK_SECRET")
-	secret = strings.TrimSpace(secret)
-	if secret == "" {
-		fmt.Println("missing webhook secret")
+	// read the webhook secret from the environment for every request,
+	// use secret.NewFile or secret.NewVault to rotate it without a restart
+	sp := secret.Env("STRIPE_WEBHOOK_SECRET")
+	if _, err := sp.Secret(context.Background()); err != nil {
+		fmt.Println("missing webhook secret:", err)
 		return
 	}
 
@@ -27,7 +28,7 @@ func main() {
 		fmt.Println("time:", time.Now().Format(time.RFC3339))
 
 		// process the incoming request
-		event, statusCode, err := wh.HandleRequest(w, r, secret)
+		event, statusCode, err := wh.HandleRequestWithSecret(w, r, sp)
 		if err != nil {
 			fmt.Println("error processing request:", err)
 			w.WriteHeader(statusCode)
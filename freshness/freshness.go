@@ -0,0 +1,78 @@
+// Package freshness detects out-of-order and stale webhook events.
+//
+// Providers do not guarantee the delivery order and retries can deliver an older event
+// after a newer one. A Guard remembers the creation time of the latest event seen
+// for every object and reports events that are older than that.
+package freshness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+	stripewh "github.com/pilinux/webhook/stripe"
+	"github.com/stripe/stripe-go/v79"
+)
+
+// Store remembers the latest event creation time per object ID.
+type Store interface {
+	// Observe stores t for key unless a later time is already stored
+	// and returns the time stored before the call (zero if none).
+	// Implementations must perform the comparison and update atomically.
+	Observe(ctx context.Context, key string, t time.Time) (previous time.Time, err error)
+}
+
+// Result is the outcome of a freshness check.
+type Result struct {
+	// Stale is true if a newer event for the same object has already been seen
+	Stale bool
+	// Latest is the creation time of the newest event seen for the object
+	Latest time.Time
+}
+
+// Guard checks events against the latest creation time seen for their object.
+type Guard struct {
+	store Store
+}
+
+// NewGuard creates a new guard backed by the given store.
+func NewGuard(store Store) *Guard {
+	return &Guard{store: store}
+}
+
+// Check records an event created at the given time for the object key.
+// Events created at the same time as the latest one are not stale,
+// as the timestamps of some providers only have second precision.
+func (g *Guard) Check(ctx context.Context, key string, created time.Time) (Result, error) {
+	previous, err := g.store.Observe(ctx, key, created)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if created.Before(previous) {
+		return Result{Stale: true, Latest: previous}, nil
+	}
+	return Result{Latest: created}, nil
+}
+
+// Stripe checks a Stripe event by the ID of its object and event.Created.
+func (g *Guard) Stripe(ctx context.Context, event stripe.Event) (Result, error) {
+	key := stripewh.ObjectID(event)
+	if key == "" {
+		return Result{}, fmt.Errorf("event %s has no object ID", event.ID)
+	}
+	return g.Check(ctx, "stripe:"+key, time.Unix(event.Created, 0))
+}
+
+// Resend checks a Resend payload by resend.ObjectID and payload.CreatedAt.
+func (g *Guard) Resend(ctx context.Context, payload resend.Payload) (Result, error) {
+	key := resend.ObjectID(payload)
+	if key == "" {
+		return Result{}, fmt.Errorf("%s event has no object ID", payload.Type)
+	}
+	if payload.CreatedAt.IsZero() {
+		return Result{}, fmt.Errorf("%s event has no creation time", payload.Type)
+	}
+	return g.Check(ctx, "resend:"+key, payload.CreatedAt.Time)
+}
@@ -0,0 +1,140 @@
+package freshness
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+	"github.com/stripe/stripe-go/v79"
+)
+
+func TestGuardCheck(t *testing.T) {
+	ctx := context.Background()
+	g := NewGuard(NewMemoryStore())
+	t0 := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		key     string
+		created time.Time
+		want    Result
+	}{
+		{"first", "a", t0, Result{Latest: t0}},
+		{"newer", "a", t0.Add(time.Second), Result{Latest: t0.Add(time.Second)}},
+		{"same second", "a", t0.Add(time.Second), Result{Latest: t0.Add(time.Second)}},
+		{"older", "a", t0, Result{Stale: true, Latest: t0.Add(time.Second)}},
+		{"other object", "b", t0, Result{Latest: t0}},
+	}
+	for _, tt := range tests {
+		got, err := g.Check(ctx, tt.key, tt.created)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Stale != tt.want.Stale || !got.Latest.Equal(tt.want.Latest) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGuardStripe(t *testing.T) {
+	ctx := context.Background()
+	g := NewGuard(NewMemoryStore())
+
+	event := func(id string, created int64) stripe.Event {
+		return stripe.Event{
+			ID:      "evt_" + id,
+			Created: created,
+			Data:    &stripe.EventData{Object: map[string]interface{}{"id": "sub_1"}},
+		}
+	}
+
+	if res, err := g.Stripe(ctx, event("2", 200)); err != nil || res.Stale {
+		t.Fatalf("got %+v, %v", res, err)
+	}
+	res, err := g.Stripe(ctx, event("1", 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Stale || res.Latest.Unix() != 200 {
+		t.Errorf("got %+v, want stale with latest 200", res)
+	}
+
+	if _, err := g.Stripe(ctx, stripe.Event{ID: "evt_3"}); err == nil || !strings.Contains(err.Error(), "no object ID") {
+		t.Errorf("got %v, want missing object ID error", err)
+	}
+}
+
+func TestGuardResend(t *testing.T) {
+	ctx := context.Background()
+	g := NewGuard(NewMemoryStore())
+
+	payload := func(body string) resend.Payload {
+		t.Helper()
+		var p resend.Payload
+		if err := json.Unmarshal([]byte(body), &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	delivered := payload(`{"type":"email.delivered","created_at":"2024-11-22T23:41:12.126Z","data":{"email_id":"e1"}}`)
+	sent := payload(`{"type":"email.sent","created_at":"2024-11-22T23:41:11.000Z","data":{"email_id":"e1"}}`)
+
+	if res, err := g.Resend(ctx, delivered); err != nil || res.Stale {
+		t.Fatalf("got %+v, %v", res, err)
+	}
+	res, err := g.Resend(ctx, sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Stale || !res.Latest.Equal(delivered.CreatedAt.Time) {
+		t.Errorf("got %+v, want stale with latest %v", res, delivered.CreatedAt.Time)
+	}
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"type":"email.sent","created_at":"2024-11-22T23:41:12Z","data":{}}`, "email.sent event has no object ID"},
+		{`{"type":"email.sent","data":{"email_id":"e1"}}`, "email.sent event has no creation time"},
+	}
+	for _, tt := range tests {
+		if _, err := g.Resend(ctx, payload(tt.body)); err == nil || err.Error() != tt.want {
+			t.Errorf("got %v, want %q", err, tt.want)
+		}
+	}
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	t0 := time.Unix(1700000000, 0)
+
+	for key, created := range map[string]time.Time{
+		"old":    t0.Add(-time.Hour),
+		"cutoff": t0,
+		"new":    t0.Add(time.Hour),
+	} {
+		if _, err := s.Observe(ctx, key, created); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := s.Prune(t0); n != 1 {
+		t.Errorf("Prune removed %d objects, want 1", n)
+	}
+	// a pruned object starts over
+	previous, err := s.Observe(ctx, "old", t0.Add(-2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !previous.IsZero() {
+		t.Errorf("previous for pruned object = %v, want zero", previous)
+	}
+	if previous, _ := s.Observe(ctx, "cutoff", t0); !previous.Equal(t0) {
+		t.Errorf("previous for kept object = %v, want %v", previous, t0)
+	}
+}
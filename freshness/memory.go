@@ -0,0 +1,47 @@
+package freshness
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store safe for concurrent use.
+type MemoryStore struct {
+	mu     sync.Mutex
+	latest map[string]time.Time
+}
+
+// NewMemoryStore creates a new empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{latest: make(map[string]time.Time)}
+}
+
+// Observe stores t for key unless a later time is already stored
+// and returns the time stored before the call.
+func (s *MemoryStore) Observe(_ context.Context, key string, t time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.latest[key]
+	if t.After(previous) {
+		s.latest[key] = t
+	}
+	return previous, nil
+}
+
+// Prune forgets all objects whose latest event was created before the given time,
+// e.g. beyond the provider's retry window, and returns the number of removed objects.
+func (s *MemoryStore) Prune(before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for key, t := range s.latest {
+		if t.Before(before) {
+			delete(s.latest, key)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Handler is an http.Handler that verifies and parses incoming requests
+// with a provider and passes the event to a HandlerFunc.
+//
+// A rejected request is answered with the status code of its error.
+// If the HandlerFunc returns an error, 500 Internal Server Error is sent
+// so that the provider retries the delivery.
+type Handler struct {
+	provider   Provider
+	fn         HandlerFunc
+	middleware []Middleware
+	onError    func(r *http.Request, err error)
+}
+
+// NewHandler creates a new handler for the given provider.
+func NewHandler(p Provider, fn HandlerFunc) *Handler {
+	return &Handler{
+		provider: p,
+		fn:       fn,
+	}
+}
+
+// Use adds middleware that wraps the HandlerFunc.
+// Middleware runs in the order it was added.
+func (h *Handler) Use(mw ...Middleware) *Handler {
+	h.middleware = append(h.middleware, mw...)
+	return h
+}
+
+// OnError sets a function that is called with every rejected request
+// and every HandlerFunc error, e.g. for logging.
+func (h *Handler) OnError(fn func(r *http.Request, err error)) *Handler {
+	h.onError = fn
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := h.provider.Verify(w, r)
+	if err != nil {
+		// errors with a 2xx status code, e.g. replays, are acknowledged without processing
+		if StatusCode(err) >= 300 {
+			h.error(r, err)
+		}
+		w.WriteHeader(StatusCode(err))
+		return
+	}
+
+	event, err := h.provider.Parse(r, body)
+	if err != nil {
+		h.error(r, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	fn := h.fn
+	for i := len(h.middleware) - 1; i >= 0; i-- {
+		fn = h.middleware[i](fn)
+	}
+
+	if err = fn(r.Context(), event); err != nil {
+		h.error(r, fmt.Errorf("%s %s: %w", event.Provider, event.Type, err))
+		// allow the provider to redeliver the event despite replay protection
+		if f, ok := h.provider.(Forgetter); ok {
+			if err = f.Forget(r); err != nil {
+				h.error(r, err)
+			}
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) error(r *http.Request, err error) {
+	if h.onError != nil {
+		h.onError(r, err)
+	}
+}
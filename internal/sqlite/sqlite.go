@@ -0,0 +1,23 @@
+// Package sqlite holds the helpers shared by the SQLite stores of this module:
+// retry.SQLiteSink, svixgo.SQLiteNonceStore, suppression.SQLiteStore and contacts.SQLiteStore.
+//
+// None of the stores imports a driver; open the *sql.DB passed to their
+// constructors with any SQLite driver, e.g. modernc.org/sqlite or github.com/mattn/go-sqlite3.
+package sqlite
+
+import (
+	"time"
+)
+
+// timeFormat keeps the stored timestamps sortable as text.
+const timeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// FormatTime formats t in UTC for a TEXT column, so that ORDER BY sorts chronologically.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// ParseTime parses a timestamp written by FormatTime.
+func ParseTime(s string) (time.Time, error) {
+	return time.Parse(timeFormat, s)
+}
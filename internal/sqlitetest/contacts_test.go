@@ -0,0 +1,66 @@
+package sqlitetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pilinux/webhook/resend/contacts"
+)
+
+func TestContactsSQLiteStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := contacts.NewSQLiteStore(ctx, openDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := contacts.NewSync(store)
+
+	events := []struct {
+		body    string
+		changed bool
+	}{
+		{`{"type":"contact.created","created_at":"2024-11-22T10:00:00Z","data":{"id":"c1","audience_id":"a1","email":"b@example.com",
+			"created_at":"2024-11-22T10:00:00Z"}}`, true},
+		{`{"type":"contact.created","created_at":"2024-11-22T10:00:00Z","data":{"id":"c2","audience_id":"a1","email":"a@example.com",
+			"created_at":"2024-11-22T10:00:00Z"}}`, true},
+		{`{"type":"contact.updated","created_at":"2024-11-22T11:00:00Z","data":{"id":"c1","audience_id":"a1","email":"b@example.com",
+			"first_name":"Bea","unsubscribed":true,"created_at":"2024-11-22T10:00:00Z","updated_at":"2024-11-22T11:00:00.25Z"}}`, true},
+		// redelivered
+		{`{"type":"contact.updated","created_at":"2024-11-22T11:00:00Z","data":{"id":"c1","audience_id":"a1","email":"b@example.com",
+			"first_name":"Bea","unsubscribed":true,"created_at":"2024-11-22T10:00:00Z","updated_at":"2024-11-22T11:00:00.25Z"}}`, false},
+		{`{"type":"contact.deleted","created_at":"2024-11-22T12:00:00Z","data":{"id":"c2","audience_id":"a1","email":"a@example.com"}}`, true},
+	}
+	for i, e := range events {
+		changed, err := s.Apply(ctx, payload(t, e.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != e.changed {
+			t.Errorf("event %d: got changed %v, want %v", i+1, changed, e.changed)
+		}
+	}
+
+	contact, ok, err := s.Get(ctx, "a1", "c1")
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	if contact.FirstName != "Bea" || !contact.Unsubscribed || !contact.Version.Equal(contact.UpdatedAt) || contact.UpdatedAt.Nanosecond() != 250000000 {
+		t.Errorf("unexpected contact %+v", contact)
+	}
+
+	// the deleted contact is kept as a tombstone
+	if _, ok, err = s.Get(ctx, "a1", "c2"); err != nil || ok {
+		t.Errorf("got %v, %v for a deleted contact", ok, err)
+	}
+	if c, ok, err := store.Get(ctx, "a1", "c2"); err != nil || !ok || !c.Deleted {
+		t.Errorf("got %+v, %v, %v, want a tombstone", c, ok, err)
+	}
+
+	list, err := s.List(ctx, "a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != "c1" {
+		t.Errorf("got %+v, want only c1", list)
+	}
+}
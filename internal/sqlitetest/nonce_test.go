@@ -0,0 +1,60 @@
+package sqlitetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook/svixgo"
+)
+
+func TestSQLiteNonceStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := svixgo.NewSQLiteNonceStore(ctx, openDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// expired message IDs are removed and can be added again
+	if _, err = s.Add(ctx, "msg_1", time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if added, err := s.Add(ctx, "msg_1", time.Now().Add(time.Minute)); err != nil || !added {
+		t.Fatalf("got %v, %v, want the expired message ID to be added", added, err)
+	}
+	if added, err := s.Add(ctx, "msg_1", time.Now().Add(time.Minute)); err != nil || added {
+		t.Fatalf("got %v, %v, want the stored message ID to be rejected", added, err)
+	}
+
+	const secret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+	wh, err := svixgo.NewWebhook(secret, svixgo.WithNonceStore(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"type":"user.created"}`)
+	now := time.Now()
+	signature, err := svixgo.Sign(secret, "msg_2", now, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := http.Header{}
+	headers.Set("webhook-id", "msg_2")
+	headers.Set("webhook-timestamp", strconv.FormatInt(now.Unix(), 10))
+	headers.Set("webhook-signature", signature)
+
+	if err = wh.VerifyContext(ctx, payload, headers); err != nil {
+		t.Fatal(err)
+	}
+	if err = wh.VerifyContext(ctx, payload, headers); !errors.Is(err, svixgo.ErrReplayed) {
+		t.Fatalf("got %v, want %v", err, svixgo.ErrReplayed)
+	}
+	if err = wh.Forget(ctx, "msg_2"); err != nil {
+		t.Fatal(err)
+	}
+	if err = wh.VerifyContext(ctx, payload, headers); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,54 @@
+package sqlitetest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook/retry"
+)
+
+func TestSQLiteSink(t *testing.T) {
+	ctx := context.Background()
+	s, err := retry.NewSQLiteSink(ctx, openDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failedAt := time.Date(2024, 11, 22, 10, 0, 0, 123456789, time.UTC)
+	records := []retry.Record{
+		{ID: "msg_2", Provider: "resend", Type: "email.sent", Body: `{}`, Error: "timeout", FailedAt: failedAt.Add(time.Second)},
+		{
+			ID: "msg_1", Provider: "stripe", Type: "invoice.paid", Body: `{"id":"evt_1"}`,
+			Headers:  http.Header{"Stripe-Signature": {"t=1,v1=abc"}},
+			Error:    "database unavailable",
+			Attempts: []retry.Attempt{{Number: 1, StartedAt: failedAt, Error: "database unavailable"}},
+			FailedAt: failedAt,
+		},
+	}
+	for _, rec := range records {
+		if err = s.Put(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "msg_1" || got[1].ID != "msg_2" {
+		t.Fatalf("got %+v, want oldest first", got)
+	}
+	if !got[0].FailedAt.Equal(failedAt) || got[0].Headers.Get("Stripe-Signature") != "t=1,v1=abc" ||
+		len(got[0].Attempts) != 1 || got[0].Attempts[0].Error != "database unavailable" {
+		t.Errorf("got %+v, want %+v", got[0], records[1])
+	}
+
+	if err = s.Delete(ctx, "msg_1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = s.List(ctx); err != nil || len(got) != 1 || got[0].ID != "msg_2" {
+		t.Errorf("got %+v, %v after delete", got, err)
+	}
+}
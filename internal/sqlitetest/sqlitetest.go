@@ -0,0 +1,6 @@
+// Package sqlitetest tests the SQLite stores of github.com/pilinux/webhook
+// against modernc.org/sqlite.
+//
+// It is a separate module, so that the driver does not become a dependency
+// of the main module. Run the tests from this directory with `go test ./...`.
+package sqlitetest
@@ -0,0 +1,56 @@
+package sqlitetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+	"github.com/pilinux/webhook/resend/suppression"
+)
+
+func payload(t *testing.T, body string) resend.Payload {
+	t.Helper()
+	var p resend.Payload
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestSuppressionSQLiteStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := suppression.NewSQLiteStore(ctx, openDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := suppression.New(s)
+
+	if err = l.Apply(ctx, payload(t, `{"type":"email.bounced","created_at":"2024-11-22T10:00:00.5Z",
+		"data":{"email_id":"e1","to":["User <User@Example.com>"],"bounce":{"type":"Permanent","message":"no such user"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok, err := l.IsSuppressed(ctx, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := suppression.Entry{
+		Address:      "user@example.com",
+		Reason:       suppression.ReasonBounced,
+		Detail:       "no such user",
+		EmailID:      "e1",
+		SuppressedAt: time.Date(2024, 11, 22, 10, 0, 0, 500000000, time.UTC),
+	}
+	if !ok || entry != want {
+		t.Fatalf("got %+v, %v, want %+v", entry, ok, want)
+	}
+
+	if err = l.Remove(ctx, "USER@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err = l.IsSuppressed(ctx, "user@example.com"); err != nil || ok {
+		t.Errorf("got %v, %v after remove", ok, err)
+	}
+}
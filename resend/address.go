@@ -0,0 +1,16 @@
+package resend
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// NormalizeAddress returns the lowercase bare address of `Name <user@example.com>`
+// or `User@Example.com`, so that stores keyed by address agree on the key.
+func NormalizeAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+	return strings.ToLower(address)
+}
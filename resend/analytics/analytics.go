@@ -0,0 +1,326 @@
+// Package analytics computes engagement statistics from resend email events:
+// open rate, click-through rate, top links and click devices per subject line and tag.
+//
+// Counters are aggregated in fixed time windows by the time the email was sent,
+// so the delivery, opens and clicks of an email are counted in the same window
+// as the email itself and the rates of a window stay between 0 and 1.
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+)
+
+// Dimension is the attribute statistics are grouped by.
+type Dimension string
+
+// all dimensions
+const (
+	// BySubject groups by subject line
+	BySubject Dimension = "subject"
+	// ByTag groups by tag, the value is `name=value`
+	ByTag Dimension = "tag"
+)
+
+// LinkCount is the number of clicks on a link.
+type LinkCount struct {
+	Link   string `json:"link"`
+	Clicks int    `json:"clicks"`
+}
+
+// Stats are the counters of a single group in a single window.
+type Stats struct {
+	Dimension   Dimension `json:"dimension"`
+	Value       string    `json:"value"`
+	WindowStart time.Time `json:"window_start"`
+
+	Sent      int `json:"sent"`
+	Delivered int `json:"delivered"`
+	// Opens counts every open, UniqueOpens counts the first open per email and recipient
+	Opens       int `json:"opens"`
+	UniqueOpens int `json:"unique_opens"`
+	// Clicks counts every click, UniqueClicks counts the first click per email and recipient
+	Clicks       int `json:"clicks"`
+	UniqueClicks int `json:"unique_clicks"`
+
+	// OpenRate is UniqueOpens / Delivered
+	OpenRate float64 `json:"open_rate"`
+	// ClickThroughRate is UniqueClicks / Delivered
+	ClickThroughRate float64 `json:"click_through_rate"`
+
+	TopLinks []LinkCount    `json:"top_links,omitempty"`
+	Devices  map[Device]int `json:"devices,omitempty"`
+}
+
+// group identifies the counters of one dimension value in one window.
+type group struct {
+	dimension Dimension
+	value     string
+	window    int64
+}
+
+type counters struct {
+	sent, delivered      int
+	opens, uniqueOpens   int
+	clicks, uniqueClicks int
+	links                map[string]int
+	devices              map[Device]int
+	// opened and clicked hold the email/recipient keys seen in this group
+	opened  map[string]struct{}
+	clicked map[string]struct{}
+	// applied holds the keys of the events counted in this group
+	applied map[string]struct{}
+}
+
+// Analytics aggregates engagement counters. It is safe for concurrent use.
+type Analytics struct {
+	window   time.Duration
+	topLinks int
+
+	mu     sync.Mutex
+	groups map[group]*counters
+	// windows maps email IDs to the window of the email
+	windows map[string]int64
+}
+
+// New creates a new aggregator with the given window size, e.g. 24 * time.Hour.
+// A window of zero aggregates all events into a single window.
+// topLinks limits the number of links reported per group.
+func New(window time.Duration, topLinks int) *Analytics {
+	return &Analytics{
+		window:   window,
+		topLinks: topLinks,
+		groups:   make(map[group]*counters),
+		windows:  make(map[string]int64),
+	}
+}
+
+// Apply adds an email event to the counters of its subject and tags
+// in the window of the email. Other payloads are ignored.
+// Redeliveries of an event, recognized by its type, email ID and creation time,
+// are counted once.
+func (a *Analytics) Apply(_ context.Context, payload resend.Payload) error {
+	switch payload.Type {
+	case resend.EmailSent, resend.EmailDelivered, resend.EmailOpened, resend.EmailClicked:
+	default:
+		return nil
+	}
+
+	// repeat opens and clicks are recognized by email and recipient
+	recipient := payload.Data.EmailID
+	for _, to := range payload.Data.To {
+		recipient += "|" + to
+	}
+	key := fmt.Sprintf("%s|%s|%d", payload.Type, payload.Data.EmailID, payload.CreatedAt.UnixNano())
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	window := a.windowOf(payload)
+	for _, g := range a.groupsOf(payload, window) {
+		c := a.groups[g]
+		if c == nil {
+			c = &counters{
+				links:   make(map[string]int),
+				devices: make(map[Device]int),
+				opened:  make(map[string]struct{}),
+				clicked: make(map[string]struct{}),
+				applied: make(map[string]struct{}),
+			}
+			a.groups[g] = c
+		}
+		if _, ok := c.applied[key]; ok {
+			continue
+		}
+		c.applied[key] = struct{}{}
+
+		switch payload.Type {
+		case resend.EmailSent:
+			c.sent++
+		case resend.EmailDelivered:
+			c.delivered++
+		case resend.EmailOpened:
+			c.opens++
+			if _, ok := c.opened[recipient]; !ok {
+				c.opened[recipient] = struct{}{}
+				c.uniqueOpens++
+			}
+		case resend.EmailClicked:
+			c.clicks++
+			if _, ok := c.clicked[recipient]; !ok {
+				c.clicked[recipient] = struct{}{}
+				c.uniqueClicks++
+			}
+			if click := payload.Data.Click; click != nil {
+				if click.Link != "" {
+					c.links[click.Link]++
+				}
+				c.devices[ClassifyUserAgent(click.UserAgent)]++
+			}
+		}
+	}
+	return nil
+}
+
+// Middleware applies every payload to the aggregator before passing it on,
+// e.g. router.Use(analytics.Middleware()).
+func (a *Analytics) Middleware() resend.Middleware {
+	return func(next resend.HandlerFunc) resend.HandlerFunc {
+		return func(ctx context.Context, payload resend.Payload) error {
+			if err := a.Apply(ctx, payload); err != nil {
+				return err
+			}
+			return next(ctx, payload)
+		}
+	}
+}
+
+// Report returns the statistics of all groups of the given dimension,
+// ordered by window and value.
+func (a *Analytics) Report(dimension Dimension) []Stats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var report []Stats
+	for g, c := range a.groups {
+		if g.dimension != dimension {
+			continue
+		}
+
+		s := Stats{
+			Dimension:    g.dimension,
+			Value:        g.value,
+			Sent:         c.sent,
+			Delivered:    c.delivered,
+			Opens:        c.opens,
+			UniqueOpens:  c.uniqueOpens,
+			Clicks:       c.clicks,
+			UniqueClicks: c.uniqueClicks,
+			TopLinks:     a.top(c.links),
+		}
+		if a.window > 0 {
+			s.WindowStart = time.Unix(g.window, 0).UTC()
+		}
+		if c.delivered > 0 {
+			s.OpenRate = float64(c.uniqueOpens) / float64(c.delivered)
+			s.ClickThroughRate = float64(c.uniqueClicks) / float64(c.delivered)
+		}
+		if len(c.devices) > 0 {
+			s.Devices = make(map[Device]int, len(c.devices))
+			for d, n := range c.devices {
+				s.Devices[d] = n
+			}
+		}
+		report = append(report, s)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		if !report[i].WindowStart.Equal(report[j].WindowStart) {
+			return report[i].WindowStart.Before(report[j].WindowStart)
+		}
+		return report[i].Value < report[j].Value
+	})
+	return report
+}
+
+// WriteJSON writes the statistics of all dimensions as a JSON object keyed by dimension.
+func (a *Analytics) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[Dimension][]Stats{
+		BySubject: a.Report(BySubject),
+		ByTag:     a.Report(ByTag),
+	})
+}
+
+// Prune drops all windows that started before the given time
+// and returns the number of removed groups.
+// Events of emails in a pruned window start a new window when they arrive later.
+func (a *Analytics) Prune(before time.Time) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.window <= 0 {
+		return 0
+	}
+
+	n := 0
+	for g := range a.groups {
+		if g.window < before.Unix() {
+			delete(a.groups, g)
+			n++
+		}
+	}
+	for id, window := range a.windows {
+		if window < before.Unix() {
+			delete(a.windows, id)
+		}
+	}
+	return n
+}
+
+// windowOf returns the window of the email the payload belongs to,
+// it must be called with a.mu held.
+//
+// The window is taken from the creation time of the email in the payload data.
+// Without it, the first event seen for the email decides the window.
+func (a *Analytics) windowOf(payload resend.Payload) int64 {
+	if a.window <= 0 {
+		return 0
+	}
+
+	id := payload.Data.EmailID
+	if window, ok := a.windows[id]; ok && id != "" {
+		return window
+	}
+
+	sent := payload.Data.CreatedAt.Time
+	if sent.IsZero() {
+		sent = payload.CreatedAt.Time
+	}
+	window := sent.Truncate(a.window).Unix()
+	if id != "" {
+		a.windows[id] = window
+	}
+	return window
+}
+
+// groupsOf returns the groups a payload is counted in.
+func (a *Analytics) groupsOf(payload resend.Payload, window int64) []group {
+	var groups []group
+	if payload.Data.Subject != "" {
+		groups = append(groups, group{dimension: BySubject, value: payload.Data.Subject, window: window})
+	}
+	for name, value := range payload.Data.Tags {
+		groups = append(groups, group{dimension: ByTag, value: name + "=" + value, window: window})
+	}
+	return groups
+}
+
+// top returns the most clicked links, at most a.topLinks.
+func (a *Analytics) top(links map[string]int) []LinkCount {
+	list := make([]LinkCount, 0, len(links))
+	for link, clicks := range links {
+		list = append(list, LinkCount{Link: link, Clicks: clicks})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Clicks != list[j].Clicks {
+			return list[i].Clicks > list[j].Clicks
+		}
+		return list[i].Link < list[j].Link
+	})
+	if a.topLinks > 0 && len(list) > a.topLinks {
+		list = list[:a.topLinks]
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
@@ -0,0 +1,175 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+)
+
+func payload(t *testing.T, body string) resend.Payload {
+	t.Helper()
+	var p resend.Payload
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestApplyCountsRedeliveriesOnce(t *testing.T) {
+	ctx := context.Background()
+	a := New(24*time.Hour, 5)
+
+	events := []string{
+		`{"type":"email.sent","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","to":["a@example.com"],"subject":"Welcome"}}`,
+		`{"type":"email.delivered","created_at":"2024-11-22T10:00:05Z","data":{"email_id":"e1","to":["a@example.com"],"subject":"Welcome"}}`,
+		`{"type":"email.opened","created_at":"2024-11-22T10:05:00Z","data":{"email_id":"e1","to":["a@example.com"],"subject":"Welcome"}}`,
+		`{"type":"email.opened","created_at":"2024-11-22T10:06:00Z","data":{"email_id":"e1","to":["a@example.com"],"subject":"Welcome"}}`,
+		`{"type":"email.clicked","created_at":"2024-11-22T10:07:00Z","data":{"email_id":"e1","to":["a@example.com"],"subject":"Welcome",
+			"click":{"link":"https://example.com","userAgent":"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148"}}}`,
+	}
+	// every event is delivered twice
+	for range 2 {
+		for _, e := range events {
+			if err := a.Apply(ctx, payload(t, e)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	report := a.Report(BySubject)
+	if len(report) != 1 {
+		t.Fatalf("got %d groups, want 1", len(report))
+	}
+	s := report[0]
+	if s.Sent != 1 || s.Delivered != 1 || s.Opens != 2 || s.UniqueOpens != 1 || s.Clicks != 1 || s.UniqueClicks != 1 {
+		t.Errorf("unexpected counters: %+v", s)
+	}
+	if s.OpenRate != 1 || s.ClickThroughRate != 1 {
+		t.Errorf("got open rate %v and click-through rate %v, want 1", s.OpenRate, s.ClickThroughRate)
+	}
+	if len(s.TopLinks) != 1 || s.TopLinks[0].Clicks != 1 || s.Devices[DeviceMobile] != 1 {
+		t.Errorf("got links %+v and devices %v", s.TopLinks, s.Devices)
+	}
+}
+
+func TestApplyBucketsBySendTime(t *testing.T) {
+	ctx := context.Background()
+	a := New(24*time.Hour, 5)
+
+	// the email is sent just before midnight, delivered and opened on the next day
+	events := []string{
+		`{"type":"email.sent","created_at":"2024-11-22T23:59:58Z","data":{"email_id":"e1","created_at":"2024-11-22T23:59:57Z","to":["a@example.com"],"subject":"Welcome"}}`,
+		`{"type":"email.delivered","created_at":"2024-11-23T00:00:02Z","data":{"email_id":"e1","created_at":"2024-11-22T23:59:57Z","to":["a@example.com"],"subject":"Welcome"}}`,
+		`{"type":"email.opened","created_at":"2024-11-23T08:00:00Z","data":{"email_id":"e1","created_at":"2024-11-22T23:59:57Z","to":["a@example.com"],"subject":"Welcome"}}`,
+		// without data.created_at the window of the first event of the email is used
+		`{"type":"email.opened","created_at":"2024-11-24T08:00:00Z","data":{"email_id":"e1","to":["a@example.com"],"subject":"Welcome"}}`,
+		`{"type":"email.sent","created_at":"2024-11-23T10:00:00Z","data":{"email_id":"e2","to":["b@example.com"],"subject":"Welcome"}}`,
+		`{"type":"email.delivered","created_at":"2024-11-23T10:00:01Z","data":{"email_id":"e2","to":["b@example.com"],"subject":"Welcome"}}`,
+	}
+	for _, e := range events {
+		if err := a.Apply(ctx, payload(t, e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := a.Report(BySubject)
+	if len(report) != 2 {
+		t.Fatalf("got %d windows, want 2: %+v", len(report), report)
+	}
+	day1, day2 := report[0], report[1]
+	if !day1.WindowStart.Equal(time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC)) ||
+		day1.Sent != 1 || day1.Delivered != 1 || day1.Opens != 2 || day1.UniqueOpens != 1 || day1.OpenRate != 1 {
+		t.Errorf("unexpected first window: %+v", day1)
+	}
+	if !day2.WindowStart.Equal(time.Date(2024, 11, 23, 0, 0, 0, 0, time.UTC)) ||
+		day2.Sent != 1 || day2.Delivered != 1 || day2.Opens != 0 || day2.OpenRate != 0 {
+		t.Errorf("unexpected second window: %+v", day2)
+	}
+
+	// pruning the first day forgets its groups and emails
+	if n := a.Prune(time.Date(2024, 11, 23, 0, 0, 0, 0, time.UTC)); n != 1 {
+		t.Errorf("Prune removed %d groups, want 1", n)
+	}
+	if report = a.Report(BySubject); len(report) != 1 || !report[0].WindowStart.Equal(day2.WindowStart) {
+		t.Errorf("got %+v after Prune", report)
+	}
+	if _, ok := a.windows["e1"]; ok {
+		t.Error("e1 is still mapped to a pruned window")
+	}
+}
+
+func TestApplyGroupsByTag(t *testing.T) {
+	ctx := context.Background()
+	a := New(0, 1)
+
+	events := []string{
+		`{"type":"email.delivered","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","to":["a@example.com"],"tags":{"campaign":"spring","plan":"pro"}}}`,
+		`{"type":"email.delivered","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e2","to":["b@example.com"],"tags":[{"name":"campaign","value":"spring"}]}}`,
+		`{"type":"email.clicked","created_at":"2024-11-22T11:00:00Z","data":{"email_id":"e1","to":["a@example.com"],"tags":{"campaign":"spring","plan":"pro"},
+			"click":{"link":"https://example.com/a","userAgent":"Mozilla/5.0 (Windows NT 10.0; Win64; x64)"}}}`,
+		`{"type":"email.clicked","created_at":"2024-11-22T11:01:00Z","data":{"email_id":"e1","to":["a@example.com"],"tags":{"campaign":"spring","plan":"pro"},
+			"click":{"link":"https://example.com/a","userAgent":"Mozilla/5.0 (Windows NT 10.0; Win64; x64)"}}}`,
+		`{"type":"email.clicked","created_at":"2024-11-22T11:02:00Z","data":{"email_id":"e2","to":["b@example.com"],"tags":[{"name":"campaign","value":"spring"}],
+			"click":{"link":"https://example.com/b","userAgent":"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X)"}}}`,
+	}
+	for _, e := range events {
+		if err := a.Apply(ctx, payload(t, e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no subject, so only tags are reported
+	if report := a.Report(BySubject); len(report) != 0 {
+		t.Errorf("got %+v, want no subject groups", report)
+	}
+
+	report := a.Report(ByTag)
+	if len(report) != 2 || report[0].Value != "campaign=spring" || report[1].Value != "plan=pro" {
+		t.Fatalf("got %+v", report)
+	}
+	spring := report[0]
+	if spring.Delivered != 2 || spring.Clicks != 3 || spring.UniqueClicks != 2 || spring.ClickThroughRate != 1 || !spring.WindowStart.IsZero() {
+		t.Errorf("unexpected counters: %+v", spring)
+	}
+	if len(spring.TopLinks) != 1 || spring.TopLinks[0] != (LinkCount{Link: "https://example.com/a", Clicks: 2}) {
+		t.Errorf("got top links %+v, want only the most clicked link", spring.TopLinks)
+	}
+	if spring.Devices[DeviceDesktop] != 2 || spring.Devices[DeviceTablet] != 1 {
+		t.Errorf("got devices %v", spring.Devices)
+	}
+
+	// windows of zero are never pruned
+	if n := a.Prune(time.Now()); n != 0 {
+		t.Errorf("Prune removed %d groups, want 0", n)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	a := New(time.Hour, 5)
+	err := a.Apply(context.Background(), payload(t,
+		`{"type":"email.sent","created_at":"2024-11-22T10:30:00Z","data":{"email_id":"e1","subject":"Welcome","tags":{"plan":"pro"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = a.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got map[Dimension][]Stats
+	if err = json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	subject, tag := got[BySubject], got[ByTag]
+	if len(subject) != 1 || subject[0].Value != "Welcome" || subject[0].Sent != 1 ||
+		!subject[0].WindowStart.Equal(time.Date(2024, 11, 22, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("got subject stats %+v", subject)
+	}
+	if len(tag) != 1 || tag[0].Dimension != ByTag || tag[0].Value != "plan=pro" {
+		t.Errorf("got tag stats %+v", tag)
+	}
+}
@@ -0,0 +1,97 @@
+package analytics
+
+import (
+	"strings"
+)
+
+// Device is the category of the client that opened an email or clicked a link.
+type Device string
+
+// all device categories
+const (
+	DeviceDesktop Device = "desktop"
+	DeviceMobile  Device = "mobile"
+	DeviceTablet  Device = "tablet"
+	// DeviceProxy - image proxies and link scanners of mailbox providers
+	DeviceProxy Device = "proxy"
+	// DeviceBot - crawlers and other automated clients
+	DeviceBot     Device = "bot"
+	DeviceUnknown Device = "unknown"
+)
+
+var (
+	proxyMarkers = []string{
+		"googleimageproxy",
+		"yahoomailproxy",
+		"ggpht.com",
+		"barracuda",
+		"mimecast",
+		"proofpoint",
+	}
+	botMarkers = []string{
+		"bot",
+		"crawler",
+		"spider",
+		"slurp",
+		"curl/",
+		"wget/",
+		"python-requests",
+		"go-http-client",
+		"headlesschrome",
+	}
+	tabletMarkers = []string{
+		"ipad",
+		"tablet",
+		"kindle",
+		"silk/",
+	}
+	mobileMarkers = []string{
+		"mobile",
+		"iphone",
+		"ipod",
+		"android",
+		"windows phone",
+		"blackberry",
+	}
+	desktopMarkers = []string{
+		"windows nt",
+		"macintosh",
+		"mac os x",
+		"x11",
+		"linux",
+		"cros",
+	}
+)
+
+// ClassifyUserAgent returns the device category of a user agent string.
+func ClassifyUserAgent(userAgent string) Device {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return DeviceUnknown
+	}
+
+	switch {
+	case containsAny(ua, proxyMarkers):
+		return DeviceProxy
+	case containsAny(ua, botMarkers):
+		return DeviceBot
+	case containsAny(ua, tabletMarkers),
+		// Android tablets do not send "Mobile"
+		strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return DeviceTablet
+	case containsAny(ua, mobileMarkers):
+		return DeviceMobile
+	case containsAny(ua, desktopMarkers):
+		return DeviceDesktop
+	}
+	return DeviceUnknown
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
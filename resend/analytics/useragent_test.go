@@ -0,0 +1,32 @@
+package analytics
+
+import "testing"
+
+func TestClassifyUserAgent(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want Device
+	}{
+		{"", DeviceUnknown},
+		{"   ", DeviceUnknown},
+		{"something else", DeviceUnknown},
+		{"Mozilla/5.0 (Windows NT 5.1; rv:11.0) Gecko Firefox/11.0 (via ggpht.com GoogleImageProxy)", DeviceProxy},
+		{"YahooMailProxy; https://help.yahoo.com/kb/yahoo-mail-proxy-SLN28749.html", DeviceProxy},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", DeviceBot},
+		{"curl/8.4.0", DeviceBot},
+		{"Go-http-client/1.1", DeviceBot},
+		{"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148", DeviceTablet},
+		{"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36", DeviceTablet},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148", DeviceMobile},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36", DeviceMobile},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko)", DeviceDesktop},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", DeviceDesktop},
+		// Outlook for Windows is a desktop client, not an image proxy
+		{"Microsoft Office/16.0 (Windows NT 10.0; Microsoft Outlook 16.0.17029; Pro)", DeviceDesktop},
+	}
+	for _, tt := range tests {
+		if got := ClassifyUserAgent(tt.ua); got != tt.want {
+			t.Errorf("ClassifyUserAgent(%q) = %s, want %s", tt.ua, got, tt.want)
+		}
+	}
+}
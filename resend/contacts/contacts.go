@@ -0,0 +1,143 @@
+// Package contacts keeps a local copy of resend audiences in sync
+// from `contact.created`, `contact.updated` and `contact.deleted` events.
+//
+// Events are applied idempotently. Every contact carries a version, the time of its
+// last change, and older events never overwrite newer state. Deleted contacts are kept
+// as tombstones so that a late update cannot bring them back.
+package contacts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+)
+
+// Contact is the local copy of a resend contact.
+type Contact struct {
+	ID           string    `json:"id"`
+	AudienceID   string    `json:"audience_id"`
+	Email        string    `json:"email"`
+	FirstName    string    `json:"first_name,omitempty"`
+	LastName     string    `json:"last_name,omitempty"`
+	Unsubscribed bool      `json:"unsubscribed"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Deleted marks a tombstone
+	Deleted bool `json:"deleted,omitempty"`
+	// Version is the time of the last applied change
+	Version time.Time `json:"version"`
+}
+
+// Store keeps contacts by audience and contact ID, including tombstones.
+type Store interface {
+	// Get returns the contact, ok is false if it was never stored
+	Get(ctx context.Context, audienceID, id string) (contact Contact, ok bool, err error)
+	// Put stores the contact, replacing the previous version
+	Put(ctx context.Context, contact Contact) error
+	// List returns all contacts of the audience that are not deleted
+	List(ctx context.Context, audienceID string) ([]Contact, error)
+}
+
+// Sync applies contact events to a store.
+type Sync struct {
+	store Store
+	// serializes read-modify-write cycles on the store
+	mu sync.Mutex
+}
+
+// NewSync creates a new sync component backed by the given store.
+func NewSync(store Store) *Sync {
+	return &Sync{store: store}
+}
+
+// Apply applies a contact event to the store. It reports whether the store was changed;
+// redelivered and outdated events leave it untouched. Other payloads are ignored.
+func (s *Sync) Apply(ctx context.Context, payload resend.Payload) (changed bool, err error) {
+	if !payload.Type.IsContact() {
+		return
+	}
+
+	d := payload.Data
+	if d.ID == "" || d.AudienceID == "" {
+		err = fmt.Errorf("%s event without contact or audience ID", payload.Type)
+		return
+	}
+
+	incoming := Contact{
+		ID:           d.ID,
+		AudienceID:   d.AudienceID,
+		Email:        d.Email,
+		FirstName:    d.FirstName,
+		LastName:     d.LastName,
+		Unsubscribed: d.Unsubscribed,
+		CreatedAt:    d.CreatedAt.Time,
+		UpdatedAt:    d.UpdatedAt.Time,
+	}
+	switch {
+	case payload.Type == resend.ContactDeleted:
+		// the deletion happened when the event was created
+		incoming.Deleted = true
+		incoming.Version = payload.CreatedAt.Time
+	case !incoming.UpdatedAt.IsZero():
+		incoming.Version = incoming.UpdatedAt
+	case !incoming.CreatedAt.IsZero():
+		incoming.Version = incoming.CreatedAt
+	default:
+		incoming.Version = payload.CreatedAt.Time
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok, err := s.store.Get(ctx, d.AudienceID, d.ID)
+	if err != nil {
+		return
+	}
+	if ok {
+		if incoming.Version.Before(current.Version) {
+			// outdated
+			return
+		}
+		if incoming.Version.Equal(current.Version) && (current.Deleted || !incoming.Deleted) {
+			// redelivered, or a deletion has already been applied
+			return
+		}
+	}
+
+	if err = s.store.Put(ctx, incoming); err != nil {
+		return
+	}
+	changed = true
+	return
+}
+
+// Middleware applies every payload to the store before passing it on,
+// e.g. router.Use(sync.Middleware()).
+func (s *Sync) Middleware() resend.Middleware {
+	return func(next resend.HandlerFunc) resend.HandlerFunc {
+		return func(ctx context.Context, payload resend.Payload) error {
+			if _, err := s.Apply(ctx, payload); err != nil {
+				return err
+			}
+			return next(ctx, payload)
+		}
+	}
+}
+
+// Get returns the contact, ok is false if it does not exist or was deleted.
+func (s *Sync) Get(ctx context.Context, audienceID, id string) (Contact, bool, error) {
+	contact, ok, err := s.store.Get(ctx, audienceID, id)
+	if err != nil || !ok || contact.Deleted {
+		return Contact{}, false, err
+	}
+	return contact, true, nil
+}
+
+// List returns all contacts of the audience.
+func (s *Sync) List(ctx context.Context, audienceID string) ([]Contact, error) {
+	return s.store.List(ctx, audienceID)
+}
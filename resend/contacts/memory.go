@@ -0,0 +1,56 @@
+package contacts
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is an in-memory Store safe for concurrent use.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	audiences map[string]map[string]Contact
+}
+
+// NewMemoryStore creates a new empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{audiences: make(map[string]map[string]Contact)}
+}
+
+// Get returns the contact.
+func (s *MemoryStore) Get(_ context.Context, audienceID, id string) (Contact, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	contact, ok := s.audiences[audienceID][id]
+	return contact, ok, nil
+}
+
+// Put stores the contact, replacing the previous version.
+func (s *MemoryStore) Put(_ context.Context, contact Contact) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.audiences[contact.AudienceID] == nil {
+		s.audiences[contact.AudienceID] = make(map[string]Contact)
+	}
+	s.audiences[contact.AudienceID][contact.ID] = contact
+	return nil
+}
+
+// List returns all contacts of the audience that are not deleted, ordered by email.
+func (s *MemoryStore) List(_ context.Context, audienceID string) ([]Contact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var list []Contact
+	for _, contact := range s.audiences[audienceID] {
+		if !contact.Deleted {
+			list = append(list, contact)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Email < list[j].Email
+	})
+	return list, nil
+}
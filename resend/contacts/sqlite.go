@@ -0,0 +1,117 @@
+package contacts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/pilinux/webhook/internal/sqlite"
+)
+
+// SQLiteStore stores contacts in a SQLite table named contacts.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates a new SQLite store and the contacts table if it does not exist.
+func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS contacts (
+		audience_id TEXT NOT NULL,
+		id TEXT NOT NULL,
+		email TEXT NOT NULL,
+		first_name TEXT NOT NULL,
+		last_name TEXT NOT NULL,
+		unsubscribed INTEGER NOT NULL,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL,
+		deleted INTEGER NOT NULL,
+		version TEXT NOT NULL,
+		PRIMARY KEY (audience_id, id)
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Get returns the contact.
+func (s *SQLiteStore) Get(ctx context.Context, audienceID, id string) (Contact, bool, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT audience_id, id, email, first_name, last_name, unsubscribed, created_at, updated_at, deleted, version
+		FROM contacts WHERE audience_id = ? AND id = ?`,
+		audienceID, id,
+	)
+	contact, err := scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Contact{}, false, nil
+	}
+	if err != nil {
+		return Contact{}, false, err
+	}
+	return contact, true, nil
+}
+
+// Put stores the contact, replacing the previous version.
+func (s *SQLiteStore) Put(ctx context.Context, contact Contact) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO contacts
+		(audience_id, id, email, first_name, last_name, unsubscribed, created_at, updated_at, deleted, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		contact.AudienceID, contact.ID, contact.Email, contact.FirstName, contact.LastName,
+		contact.Unsubscribed,
+		sqlite.FormatTime(contact.CreatedAt),
+		sqlite.FormatTime(contact.UpdatedAt),
+		contact.Deleted,
+		sqlite.FormatTime(contact.Version),
+	)
+	return err
+}
+
+// List returns all contacts of the audience that are not deleted, ordered by email.
+func (s *SQLiteStore) List(ctx context.Context, audienceID string) ([]Contact, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT audience_id, id, email, first_name, last_name, unsubscribed, created_at, updated_at, deleted, version
+		FROM contacts WHERE audience_id = ? AND deleted = 0 ORDER BY email`,
+		audienceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Contact
+	for rows.Next() {
+		contact, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, contact)
+	}
+	return list, rows.Err()
+}
+
+// scan reads a contact from a row of the contacts table.
+func scan(row interface{ Scan(dest ...any) error }) (Contact, error) {
+	var (
+		contact                       Contact
+		createdAt, updatedAt, version string
+	)
+	err := row.Scan(
+		&contact.AudienceID, &contact.ID, &contact.Email, &contact.FirstName, &contact.LastName,
+		&contact.Unsubscribed, &createdAt, &updatedAt, &contact.Deleted, &version,
+	)
+	if err != nil {
+		return Contact{}, err
+	}
+
+	if contact.CreatedAt, err = sqlite.ParseTime(createdAt); err != nil {
+		return Contact{}, err
+	}
+	if contact.UpdatedAt, err = sqlite.ParseTime(updatedAt); err != nil {
+		return Contact{}, err
+	}
+	if contact.Version, err = sqlite.ParseTime(version); err != nil {
+		return Contact{}, err
+	}
+	return contact, nil
+}
@@ -0,0 +1,173 @@
+package resend
+
+// IsEmail reports whether t is an email event type.
+func (t EventType) IsEmail() bool {
+	switch t {
+	case
+		EmailSent,
+		EmailDelivered,
+		EmailDeliveryDelayed,
+		EmailComplained,
+		EmailBounced,
+		EmailOpened,
+		EmailClicked,
+		EmailFailed,
+		EmailScheduled,
+		EmailSuppressed:
+		return true
+	}
+	return false
+}
+
+// IsContact reports whether t is a contact event type.
+func (t EventType) IsContact() bool {
+	switch t {
+	case
+		ContactCreated,
+		ContactUpdated,
+		ContactDeleted:
+		return true
+	}
+	return false
+}
+
+// IsDomain reports whether t is a domain event type.
+func (t EventType) IsDomain() bool {
+	switch t {
+	case
+		DomainCreated,
+		DomainUpdated,
+		DomainDeleted:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether t is one of the known event types.
+func (t EventType) Valid() bool {
+	return t.IsEmail() || t.IsContact() || t.IsDomain()
+}
+
+// EmailEvent struct - fields of an email event.
+// CreatedAt is the creation time of the email, the time of the event is Payload.CreatedAt.
+type EmailEvent struct {
+	Type        EventType `json:"type"`
+	EmailID     string    `json:"email_id"`
+	CreatedAt   Time      `json:"created_at"`
+	From        string    `json:"from,omitempty"`
+	To          []string  `json:"to,omitempty"`
+	Cc          []string  `json:"cc,omitempty"`
+	Bcc         []string  `json:"bcc,omitempty"`
+	ReplyTo     []string  `json:"reply_to,omitempty"`
+	Subject     string    `json:"subject,omitempty"`
+	Headers     []Header  `json:"headers,omitempty"`
+	Tags        Tags      `json:"tags,omitempty"`
+	BroadcastID string    `json:"broadcast_id,omitempty"`
+
+	Bounce      *Bounce     `json:"bounce,omitempty"`
+	Open        *Open       `json:"open,omitempty"`
+	Click       *Click      `json:"click,omitempty"`
+	Failed      *Failed     `json:"failed,omitempty"`
+	ScheduledAt Time        `json:"scheduled_at"`
+	Suppressed  *Suppressed `json:"suppressed,omitempty"`
+}
+
+// ContactEvent struct - fields of a contact event.
+type ContactEvent struct {
+	Type         EventType `json:"type"`
+	ID           string    `json:"id"`
+	AudienceID   string    `json:"audience_id,omitempty"`
+	CreatedAt    Time      `json:"created_at"`
+	UpdatedAt    Time      `json:"updated_at"`
+	Email        string    `json:"email,omitempty"`
+	FirstName    string    `json:"first_name,omitempty"`
+	LastName     string    `json:"last_name,omitempty"`
+	Unsubscribed bool      `json:"unsubscribed,omitempty"`
+}
+
+// DomainEvent struct - fields of a domain event.
+type DomainEvent struct {
+	Type      EventType      `json:"type"`
+	ID        string         `json:"id"`
+	Name      string         `json:"name,omitempty"`
+	Status    string         `json:"status,omitempty"`
+	Region    string         `json:"region,omitempty"`
+	CreatedAt Time           `json:"created_at"`
+	Records   []DomainRecord `json:"records,omitempty"`
+}
+
+// AsEmailEvent returns the email fields of the payload.
+// ok is false if the payload is not an email event.
+func (p Payload) AsEmailEvent() (event *EmailEvent, ok bool) {
+	if !p.Type.IsEmail() {
+		return
+	}
+
+	d := p.Data
+	event = &EmailEvent{
+		Type:        p.Type,
+		EmailID:     d.EmailID,
+		CreatedAt:   d.CreatedAt,
+		From:        d.From,
+		To:          d.To,
+		Cc:          d.Cc,
+		Bcc:         d.Bcc,
+		ReplyTo:     d.ReplyTo,
+		Subject:     d.Subject,
+		Headers:     d.Headers,
+		Tags:        d.Tags,
+		BroadcastID: d.BroadcastID,
+		Bounce:      d.Bounce,
+		Open:        d.Open,
+		Click:       d.Click,
+		Failed:      d.Failed,
+		ScheduledAt: d.ScheduledAt,
+		Suppressed:  d.Suppressed,
+	}
+	ok = true
+	return
+}
+
+// AsContactEvent returns the contact fields of the payload.
+// ok is false if the payload is not a contact event.
+func (p Payload) AsContactEvent() (event *ContactEvent, ok bool) {
+	if !p.Type.IsContact() {
+		return
+	}
+
+	d := p.Data
+	event = &ContactEvent{
+		Type:         p.Type,
+		ID:           d.ID,
+		AudienceID:   d.AudienceID,
+		CreatedAt:    d.CreatedAt,
+		UpdatedAt:    d.UpdatedAt,
+		Email:        d.Email,
+		FirstName:    d.FirstName,
+		LastName:     d.LastName,
+		Unsubscribed: d.Unsubscribed,
+	}
+	ok = true
+	return
+}
+
+// AsDomainEvent returns the domain fields of the payload.
+// ok is false if the payload is not a domain event.
+func (p Payload) AsDomainEvent() (event *DomainEvent, ok bool) {
+	if !p.Type.IsDomain() {
+		return
+	}
+
+	d := p.Data
+	event = &DomainEvent{
+		Type:      p.Type,
+		ID:        d.ID,
+		Name:      d.Name,
+		Status:    d.Status,
+		Region:    d.Region,
+		CreatedAt: d.CreatedAt,
+		Records:   d.Records,
+	}
+	ok = true
+	return
+}
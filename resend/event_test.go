@@ -0,0 +1,91 @@
+package resend
+
+import "testing"
+
+func TestEventTypeKinds(t *testing.T) {
+	tests := []struct {
+		t                      EventType
+		email, contact, domain bool
+	}{
+		{EmailSent, true, false, false},
+		{EmailSuppressed, true, false, false},
+		{ContactUpdated, false, true, false},
+		{DomainDeleted, false, false, true},
+		{"email.unknown", false, false, false},
+		{"", false, false, false},
+	}
+	for _, tt := range tests {
+		if tt.t.IsEmail() != tt.email || tt.t.IsContact() != tt.contact || tt.t.IsDomain() != tt.domain {
+			t.Errorf("%q: got email %v, contact %v, domain %v", tt.t, tt.t.IsEmail(), tt.t.IsContact(), tt.t.IsDomain())
+		}
+		if valid := tt.email || tt.contact || tt.domain; tt.t.Valid() != valid {
+			t.Errorf("%q: Valid() = %v, want %v", tt.t, tt.t.Valid(), valid)
+		}
+	}
+}
+
+func TestAsEmailEvent(t *testing.T) {
+	p := decodePayload(t, `{"type":"email.bounced","created_at":"2024-11-22T10:00:05Z","data":{
+		"email_id":"e1","created_at":"2024-11-22T10:00:00Z","from":"Acme <no-reply@acme.com>","to":["a@example.com"],
+		"subject":"Welcome","tags":{"plan":"pro"},"broadcast_id":"b1","bounce":{"type":"Permanent"}}}`)
+
+	event, ok := p.AsEmailEvent()
+	if !ok {
+		t.Fatal("got ok false for an email event")
+	}
+	if event.Type != EmailBounced || event.EmailID != "e1" || !event.CreatedAt.Equal(p.Data.CreatedAt.Time) ||
+		event.From != "Acme <no-reply@acme.com>" || len(event.To) != 1 || event.Subject != "Welcome" ||
+		event.Tags["plan"] != "pro" || event.BroadcastID != "b1" || !event.Bounce.IsHard() {
+		t.Errorf("got %+v", event)
+	}
+
+	if _, ok = p.AsContactEvent(); ok {
+		t.Error("got ok true from AsContactEvent for an email event")
+	}
+	if _, ok = p.AsDomainEvent(); ok {
+		t.Error("got ok true from AsDomainEvent for an email event")
+	}
+}
+
+func TestAsContactEvent(t *testing.T) {
+	p := decodePayload(t, `{"type":"contact.updated","created_at":"2024-11-22T12:00:00Z","data":{
+		"id":"c1","audience_id":"a1","created_at":"2024-11-20T08:00:00Z","updated_at":"2024-11-22T12:00:00Z",
+		"email":"user@example.com","first_name":"Ada","last_name":"Lovelace","unsubscribed":true}}`)
+
+	event, ok := p.AsContactEvent()
+	if !ok {
+		t.Fatal("got ok false for a contact event")
+	}
+	if event.Type != ContactUpdated || event.ID != "c1" || event.AudienceID != "a1" || event.Email != "user@example.com" ||
+		event.FirstName != "Ada" || event.LastName != "Lovelace" || !event.Unsubscribed ||
+		!event.UpdatedAt.Equal(p.CreatedAt.Time) || !event.CreatedAt.Before(event.UpdatedAt.Time) {
+		t.Errorf("got %+v", event)
+	}
+
+	if _, ok = p.AsEmailEvent(); ok {
+		t.Error("got ok true from AsEmailEvent for a contact event")
+	}
+}
+
+func TestAsDomainEvent(t *testing.T) {
+	p := decodePayload(t, `{"type":"domain.created","created_at":"2024-11-22T12:00:00Z","data":{
+		"id":"d1","name":"example.com","status":"not_started","region":"eu-west-1","records":[{"record":"DKIM","type":"TXT"}]}}`)
+
+	event, ok := p.AsDomainEvent()
+	if !ok {
+		t.Fatal("got ok false for a domain event")
+	}
+	if event.Type != DomainCreated || event.ID != "d1" || event.Name != "example.com" || event.Status != "not_started" ||
+		event.Region != "eu-west-1" || len(event.Records) != 1 || event.Records[0].Record != "DKIM" {
+		t.Errorf("got %+v", event)
+	}
+
+	// unknown types are no event kind at all
+	p.Type = "domain.unknown"
+	if _, ok = p.AsDomainEvent(); ok {
+		t.Error("got ok true for an unknown type")
+	}
+	if _, ok = p.AsEmailEvent(); ok {
+		t.Error("got ok true for an unknown type")
+	}
+}
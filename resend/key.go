@@ -0,0 +1,10 @@
+package resend
+
+// ObjectID returns the ID of the entity the payload belongs to:
+// the email ID for email events and the contact ID for contact events.
+func ObjectID(payload Payload) string {
+	if payload.Data.EmailID != "" {
+		return payload.Data.EmailID
+	}
+	return payload.Data.ID
+}
@@ -1,5 +1,9 @@
 package resend
 
+import (
+	"encoding/json"
+)
+
 // EventType represents the type of event
 type EventType string
 
@@ -12,9 +16,15 @@ all event types
 - email.bounced
 - email.opened
 - email.clicked
+- email.failed
+- email.scheduled
+- email.suppressed
 - contact.created
 - contact.updated
 - contact.deleted
+- domain.created
+- domain.updated
+- domain.deleted
 */
 const (
 	EmailSent            EventType = "email.sent"
@@ -24,42 +34,174 @@ const (
 	EmailBounced         EventType = "email.bounced"
 	EmailOpened          EventType = "email.opened"
 	EmailClicked         EventType = "email.clicked"
+	EmailFailed          EventType = "email.failed"
+	EmailScheduled       EventType = "email.scheduled"
+	EmailSuppressed      EventType = "email.suppressed"
 	ContactCreated       EventType = "contact.created"
 	ContactUpdated       EventType = "contact.updated"
 	ContactDeleted       EventType = "contact.deleted"
+	DomainCreated        EventType = "domain.created"
+	DomainUpdated        EventType = "domain.updated"
+	DomainDeleted        EventType = "domain.deleted"
 )
 
 // Payload struct to process the incoming request over webhook
 type Payload struct {
 	Type      EventType `json:"type"`
-	CreatedAt string    `json:"created_at"`
+	CreatedAt Time      `json:"created_at"`
 	Data      Data      `json:"data"`
 }
 
 // Data struct
 type Data struct {
 	// for email events
-	CreatedAt string   `json:"created_at,omitempty"`
+	CreatedAt Time     `json:"created_at"`
 	EmailID   string   `json:"email_id,omitempty"`
 	From      string   `json:"from,omitempty"`
 	To        []string `json:"to,omitempty"`
-	Click     *Click   `json:"click,omitempty"`
+	Cc        []string `json:"cc,omitempty"`
+	Bcc       []string `json:"bcc,omitempty"`
+	ReplyTo   []string `json:"reply_to,omitempty"`
 	Subject   string   `json:"subject,omitempty"`
+	Headers   []Header `json:"headers,omitempty"`
+	Tags      Tags     `json:"tags,omitempty"`
+	// set when the email was sent as part of a broadcast
+	BroadcastID string `json:"broadcast_id,omitempty"`
+
+	// for email.bounced events
+	Bounce *Bounce `json:"bounce,omitempty"`
+	// for email.opened events
+	Open *Open `json:"open,omitempty"`
+	// for email.clicked events
+	Click *Click `json:"click,omitempty"`
+
+	// for email.failed events
+	Failed *Failed `json:"failed,omitempty"`
+	// for email.scheduled events
+	ScheduledAt Time `json:"scheduled_at"`
+	// for email.suppressed events
+	Suppressed *Suppressed `json:"suppressed,omitempty"`
+
+	// for contact and domain events
+	ID string `json:"id,omitempty"`
 
 	// for contact events
-	ID           string `json:"id,omitempty"`
 	AudienceID   string `json:"audience_id,omitempty"`
-	UpdatedAt    string `json:"updated_at,omitempty"`
+	UpdatedAt    Time   `json:"updated_at"`
 	Email        string `json:"email,omitempty"`
 	FirstName    string `json:"first_name,omitempty"`
 	LastName     string `json:"last_name,omitempty"`
 	Unsubscribed bool   `json:"unsubscribed,omitempty"`
+
+	// for domain events
+	Name    string         `json:"name,omitempty"`
+	Status  string         `json:"status,omitempty"`
+	Region  string         `json:"region,omitempty"`
+	Records []DomainRecord `json:"records,omitempty"`
+
+	// Raw holds the original JSON of the data object,
+	// including fields that are not mapped to this struct
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the data object and keeps a copy of the raw JSON.
+func (d *Data) UnmarshalJSON(b []byte) error {
+	// the alias type drops this method and prevents infinite recursion
+	type data Data
+	if err := json.Unmarshal(b, (*data)(d)); err != nil {
+		return err
+	}
+	d.Raw = append(json.RawMessage(nil), b...)
+	return nil
+}
+
+// Header struct - custom email header
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Tags - custom tags of an email, by name
+type Tags map[string]string
+
+// UnmarshalJSON accepts tags both as an object and as an array of name/value pairs.
+func (t *Tags) UnmarshalJSON(b []byte) error {
+	var m map[string]string
+	if err := json.Unmarshal(b, &m); err == nil {
+		*t = m
+		return nil
+	}
+
+	var list []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	if list == nil {
+		*t = nil
+		return nil
+	}
+	*t = make(Tags, len(list))
+	for _, tag := range list {
+		(*t)[tag.Name] = tag.Value
+	}
+	return nil
+}
+
+// Bounce struct
+type Bounce struct {
+	Message string `json:"message,omitempty"`
+	// e.g. General, NoEmail, Suppressed, MailboxFull
+	SubType string `json:"subType,omitempty"`
+	// Permanent, Transient or Undetermined
+	Type string `json:"type,omitempty"`
+}
+
+// IsHard reports whether the bounce is permanent and the address should not be used again.
+func (b *Bounce) IsHard() bool {
+	return b != nil && b.Type == "Permanent"
+}
+
+// IsSoft reports whether the bounce is temporary.
+func (b *Bounce) IsSoft() bool {
+	return b != nil && b.Type == "Transient"
+}
+
+// Open struct
+type Open struct {
+	IPAddress string `json:"ipAddress,omitempty"`
+	Timestamp Time   `json:"timestamp"`
+	UserAgent string `json:"userAgent,omitempty"`
 }
 
 // Click struct
 type Click struct {
 	IPAddress string `json:"ipAddress,omitempty"`
 	Link      string `json:"link,omitempty"`
-	Timestamp string `json:"timestamp,omitempty"`
+	Timestamp Time   `json:"timestamp"`
 	UserAgent string `json:"userAgent,omitempty"`
 }
+
+// Failed struct
+type Failed struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// Suppressed struct
+type Suppressed struct {
+	Message string `json:"message,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// DomainRecord struct - DNS record of a domain
+type DomainRecord struct {
+	Record   string `json:"record,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type,omitempty"`
+	TTL      string `json:"ttl,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+}
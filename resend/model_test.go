@@ -0,0 +1,160 @@
+package resend
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPayloadScheduledAt(t *testing.T) {
+	var p Payload
+	body := `{"type":"email.scheduled","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","scheduled_at":"2024-11-23 08:00:00+00:00"}}`
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 11, 23, 8, 0, 0, 0, time.UTC)
+	if !p.Data.ScheduledAt.Equal(want) {
+		t.Errorf("got %s, want %s", p.Data.ScheduledAt, want)
+	}
+
+	event, ok := p.AsEmailEvent()
+	if !ok || !event.ScheduledAt.Equal(want) {
+		t.Errorf("got %+v, want email event scheduled at %s", event, want)
+	}
+
+	body = `{"type":"email.scheduled","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","scheduled_at":"tomorrow"}}`
+	if err := json.Unmarshal([]byte(body), &p); err == nil {
+		t.Error("got nil error for a malformed scheduled_at")
+	}
+}
+
+func decodePayload(t *testing.T, body string) Payload {
+	t.Helper()
+	var p Payload
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPayloadFailedAndSuppressed(t *testing.T) {
+	p := decodePayload(t, `{"type":"email.failed","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","failed":{"reason":"reached_daily_quota"}}}`)
+	if p.Type != EmailFailed || p.Data.Failed == nil || p.Data.Failed.Reason != "reached_daily_quota" {
+		t.Errorf("got %+v", p)
+	}
+
+	p = decodePayload(t, `{"type":"email.suppressed","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1",
+		"suppressed":{"type":"OnAccountSuppressionList","message":"previously bounced"}}}`)
+	if p.Type != EmailSuppressed || p.Data.Suppressed == nil ||
+		p.Data.Suppressed.Type != "OnAccountSuppressionList" || p.Data.Suppressed.Message != "previously bounced" {
+		t.Errorf("got %+v", p)
+	}
+}
+
+func TestPayloadDomain(t *testing.T) {
+	p := decodePayload(t, `{"type":"domain.updated","created_at":"2024-11-22T10:00:00Z","data":{
+		"id":"d1","name":"example.com","status":"verified","region":"us-east-1","created_at":"2024-11-20T08:00:00.000Z",
+		"records":[{"record":"SPF","name":"send","type":"MX","ttl":"Auto","status":"verified","value":"feedback-smtp.us-east-1.amazonses.com","priority":10}]}}`)
+
+	d := p.Data
+	if p.Type != DomainUpdated || d.ID != "d1" || d.Name != "example.com" || d.Status != "verified" || d.Region != "us-east-1" {
+		t.Errorf("got %+v", d)
+	}
+	if !d.CreatedAt.Equal(time.Date(2024, 11, 20, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("got created_at %s", d.CreatedAt)
+	}
+	if len(d.Records) != 1 || d.Records[0].Record != "SPF" || d.Records[0].Type != "MX" || d.Records[0].Priority != 10 {
+		t.Errorf("got records %+v", d.Records)
+	}
+}
+
+func TestTagsUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		body string
+		want Tags
+	}{
+		{`{"campaign":"spring","plan":"pro"}`, Tags{"campaign": "spring", "plan": "pro"}},
+		{`[{"name":"campaign","value":"spring"},{"name":"plan","value":"pro"}]`, Tags{"campaign": "spring", "plan": "pro"}},
+		{`[]`, Tags{}},
+		{`null`, nil},
+	}
+	for _, tt := range tests {
+		var got Tags
+		if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+			t.Errorf("%s: %v", tt.body, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.body, got, tt.want)
+			continue
+		}
+		for name, value := range tt.want {
+			if got[name] != value {
+				t.Errorf("%s: got %v, want %v", tt.body, got, tt.want)
+			}
+		}
+	}
+
+	var tags Tags
+	if err := json.Unmarshal([]byte(`"campaign"`), &tags); err == nil {
+		t.Error("got nil error for a string")
+	}
+}
+
+func TestDataRaw(t *testing.T) {
+	p := decodePayload(t, `{"type":"email.sent","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","tags":[{"name":"plan","value":"pro"}],"future_field":{"x":1}}}`)
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(p.Data.Raw, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if string(raw["future_field"]) != `{"x":1}` {
+		t.Errorf("got raw %s, want the unmapped field", p.Data.Raw)
+	}
+	if p.Data.EmailID != "e1" || p.Data.Tags["plan"] != "pro" {
+		t.Errorf("got %+v", p.Data)
+	}
+
+	// Raw is not marshaled back into the data object
+	b, err := json.Marshal(p.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "future_field") || strings.Contains(string(b), "Raw") {
+		t.Errorf("got %s", b)
+	}
+}
+
+func TestBounce(t *testing.T) {
+	tests := []struct {
+		bounce     *Bounce
+		hard, soft bool
+	}{
+		{nil, false, false},
+		{&Bounce{Type: "Permanent", SubType: "NoEmail"}, true, false},
+		{&Bounce{Type: "Transient", SubType: "MailboxFull"}, false, true},
+		{&Bounce{Type: "Undetermined"}, false, false},
+	}
+	for _, tt := range tests {
+		if tt.bounce.IsHard() != tt.hard || tt.bounce.IsSoft() != tt.soft {
+			t.Errorf("%+v: got hard %v, soft %v", tt.bounce, tt.bounce.IsHard(), tt.bounce.IsSoft())
+		}
+	}
+
+	p := decodePayload(t, `{"type":"email.bounced","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1",
+		"bounce":{"message":"mailbox full","subType":"MailboxFull","type":"Transient"}}}`)
+	if b := p.Data.Bounce; !b.IsSoft() || b.SubType != "MailboxFull" || b.Message != "mailbox full" {
+		t.Errorf("got %+v", b)
+	}
+}
+
+func TestPayloadOpen(t *testing.T) {
+	p := decodePayload(t, `{"type":"email.opened","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1",
+		"open":{"ipAddress":"192.0.2.1","timestamp":"2024-11-22T10:00:00.123Z","userAgent":"Mozilla/5.0"}}}`)
+	o := p.Data.Open
+	if o == nil || o.IPAddress != "192.0.2.1" || o.UserAgent != "Mozilla/5.0" ||
+		!o.Timestamp.Equal(time.Date(2024, 11, 22, 10, 0, 0, 123000000, time.UTC)) {
+		t.Errorf("got %+v", o)
+	}
+}
@@ -0,0 +1,65 @@
+package resend
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/standardwebhooks"
+	"github.com/pilinux/webhook/svixgo"
+)
+
+// ProviderName is the name of the resend provider in webhook.Event.
+const ProviderName = "resend"
+
+// Provider implements webhook.Provider for resend.
+// The typed object of its events is a Payload.
+//
+// Verify and Forget are those of standardwebhooks.Verifier: a replayed message
+// is acknowledged with 200 OK, as resend retries every non-2xx response.
+type Provider struct {
+	*standardwebhooks.Verifier
+}
+
+var (
+	_ webhook.Provider  = (*Provider)(nil)
+	_ webhook.Forgetter = (*Provider)(nil)
+)
+
+// NewProvider creates a new resend provider for the given webhook instance.
+func NewProvider(wh *svixgo.Webhook, opts ...Option) *Provider {
+	return &Provider{Verifier: standardwebhooks.NewVerifier(wh, opts...)}
+}
+
+// NewProviderWithSecret creates a new resend provider that creates the webhook instance from the
+// signing secret read from sp for every request, so that the secret can rotate without a restart.
+// Use WithWebhookOptions to configure the webhook instance.
+func NewProviderWithSecret(sp webhook.SecretProvider, opts ...Option) *Provider {
+	return &Provider{Verifier: standardwebhooks.NewVerifierWithSecret(sp, opts...)}
+}
+
+// Parse binds the verified body to an event.
+//
+// resend payloads carry no event ID, so the ID is the svix message ID of the
+// `svix-id` header, which is stable across redeliveries and is the same as
+// standardwebhooks.Message.ID.
+func (p *Provider) Parse(r *http.Request, body []byte) (webhook.Event, error) {
+	msgID := svixgo.MessageID(r.Header)
+	if msgID == "" {
+		return webhook.Event{}, svixgo.ErrRequiredHeaders
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return webhook.Event{}, err
+	}
+
+	return webhook.Event{
+		Provider: ProviderName,
+		ID:       msgID,
+		Type:     string(payload.Type),
+		Created:  payload.CreatedAt.Time,
+		Raw:      body,
+		Object:   payload,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package resend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/standardwebhooks"
+	"github.com/pilinux/webhook/svixgo"
+)
+
+func TestProviderEventIDMatchesMessageID(t *testing.T) {
+	const body = `{"type":"email.delivered","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1"}}`
+
+	var events []webhook.Event
+	h := webhook.NewHandler(NewProviderWithSecret(webhook.StaticSecret(testSecret)), func(_ context.Context, e webhook.Event) error {
+		events = append(events, e)
+		return nil
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, signedRequest(t, "msg_1", body))
+	if w.Code != http.StatusOK || len(events) != 1 {
+		t.Fatalf("got status %d and %d events", w.Code, len(events))
+	}
+
+	wh, err := svixgo.NewWebhook(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, _, err := standardwebhooks.HandleRequest[Payload](httptest.NewRecorder(), signedRequest(t, "msg_1", body), wh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if events[0].ID != "msg_1" || events[0].ID != msg.ID {
+		t.Errorf("got event ID %q and message ID %q, want msg_1", events[0].ID, msg.ID)
+	}
+	if events[0].Type != string(EmailDelivered) || events[0].Object.(Payload).Data.EmailID != "e1" {
+		t.Errorf("unexpected event %+v", events[0])
+	}
+}
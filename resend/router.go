@@ -0,0 +1,150 @@
+package resend
+
+import (
+	"context"
+
+	"github.com/pilinux/webhook/standardwebhooks"
+)
+
+// Middleware wraps the callback of every route, e.g. for logging or error handling.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Router dispatches payloads to the callback registered for their event type.
+// It is a standardwebhooks.Router of Payload keyed by EventType.
+type Router struct {
+	router *standardwebhooks.Router[Payload]
+}
+
+// NewRouter creates a new router without routes.
+func NewRouter() *Router {
+	return &Router{router: standardwebhooks.NewRouter[Payload]()}
+}
+
+// On registers the callback for the given event type, replacing any previous one.
+func (r *Router) On(eventType EventType, fn HandlerFunc) *Router {
+	r.router.On(string(eventType), handlerFunc(fn))
+	return r
+}
+
+// Fallback registers the callback for event types without a route.
+func (r *Router) Fallback(fn HandlerFunc) *Router {
+	r.router.Fallback(handlerFunc(fn))
+	return r
+}
+
+// Use adds middleware that wraps every callback, including the fallback.
+// Middleware runs in the order it was added.
+func (r *Router) Use(mw ...Middleware) *Router {
+	for _, m := range mw {
+		r.router.Use(middleware(m))
+	}
+	return r
+}
+
+// Dispatch passes the payload to the callback registered for its event type,
+// or to the fallback. Payloads without a callback are ignored.
+func (r *Router) Dispatch(ctx context.Context, payload Payload) error {
+	return r.router.Dispatch(ctx, standardwebhooks.Message[Payload]{
+		Type:    string(payload.Type),
+		Payload: payload,
+	})
+}
+
+// handlerFunc adapts fn to the standardwebhooks router.
+func handlerFunc(fn HandlerFunc) standardwebhooks.HandlerFunc[Payload] {
+	return func(ctx context.Context, msg standardwebhooks.Message[Payload]) error {
+		return fn(ctx, msg.Payload)
+	}
+}
+
+// middleware adapts mw to the standardwebhooks router.
+func middleware(mw Middleware) standardwebhooks.Middleware[Payload] {
+	return func(next standardwebhooks.HandlerFunc[Payload]) standardwebhooks.HandlerFunc[Payload] {
+		return func(ctx context.Context, msg standardwebhooks.Message[Payload]) error {
+			return mw(func(ctx context.Context, payload Payload) error {
+				msg.Payload = payload
+				return next(ctx, msg)
+			})(ctx, msg.Payload)
+		}
+	}
+}
+
+// OnEmailSent registers the callback for `email.sent` events.
+func (r *Router) OnEmailSent(fn HandlerFunc) *Router {
+	return r.On(EmailSent, fn)
+}
+
+// OnEmailDelivered registers the callback for `email.delivered` events.
+func (r *Router) OnEmailDelivered(fn HandlerFunc) *Router {
+	return r.On(EmailDelivered, fn)
+}
+
+// OnEmailDeliveryDelayed registers the callback for `email.delivery_delayed` events.
+func (r *Router) OnEmailDeliveryDelayed(fn HandlerFunc) *Router {
+	return r.On(EmailDeliveryDelayed, fn)
+}
+
+// OnEmailComplained registers the callback for `email.complained` events.
+func (r *Router) OnEmailComplained(fn HandlerFunc) *Router {
+	return r.On(EmailComplained, fn)
+}
+
+// OnEmailBounced registers the callback for `email.bounced` events.
+func (r *Router) OnEmailBounced(fn HandlerFunc) *Router {
+	return r.On(EmailBounced, fn)
+}
+
+// OnEmailOpened registers the callback for `email.opened` events.
+func (r *Router) OnEmailOpened(fn HandlerFunc) *Router {
+	return r.On(EmailOpened, fn)
+}
+
+// OnEmailClicked registers the callback for `email.clicked` events.
+func (r *Router) OnEmailClicked(fn HandlerFunc) *Router {
+	return r.On(EmailClicked, fn)
+}
+
+// OnEmailFailed registers the callback for `email.failed` events.
+func (r *Router) OnEmailFailed(fn HandlerFunc) *Router {
+	return r.On(EmailFailed, fn)
+}
+
+// OnEmailScheduled registers the callback for `email.scheduled` events.
+func (r *Router) OnEmailScheduled(fn HandlerFunc) *Router {
+	return r.On(EmailScheduled, fn)
+}
+
+// OnEmailSuppressed registers the callback for `email.suppressed` events.
+func (r *Router) OnEmailSuppressed(fn HandlerFunc) *Router {
+	return r.On(EmailSuppressed, fn)
+}
+
+// OnContactCreated registers the callback for `contact.created` events.
+func (r *Router) OnContactCreated(fn HandlerFunc) *Router {
+	return r.On(ContactCreated, fn)
+}
+
+// OnContactUpdated registers the callback for `contact.updated` events.
+func (r *Router) OnContactUpdated(fn HandlerFunc) *Router {
+	return r.On(ContactUpdated, fn)
+}
+
+// OnContactDeleted registers the callback for `contact.deleted` events.
+func (r *Router) OnContactDeleted(fn HandlerFunc) *Router {
+	return r.On(ContactDeleted, fn)
+}
+
+// OnDomainCreated registers the callback for `domain.created` events.
+func (r *Router) OnDomainCreated(fn HandlerFunc) *Router {
+	return r.On(DomainCreated, fn)
+}
+
+// OnDomainUpdated registers the callback for `domain.updated` events.
+func (r *Router) OnDomainUpdated(fn HandlerFunc) *Router {
+	return r.On(DomainUpdated, fn)
+}
+
+// OnDomainDeleted registers the callback for `domain.deleted` events.
+func (r *Router) OnDomainDeleted(fn HandlerFunc) *Router {
+	return r.On(DomainDeleted, fn)
+}
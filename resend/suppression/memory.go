@@ -0,0 +1,44 @@
+package suppression
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore is an in-memory Store safe for concurrent use.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore creates a new empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+// Put stores the entry, replacing an existing entry for the same address.
+func (s *MemoryStore) Put(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.Address] = entry
+	return nil
+}
+
+// Get returns the entry for the address.
+func (s *MemoryStore) Get(_ context.Context, address string) (Entry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[address]
+	return entry, ok, nil
+}
+
+// Delete removes the entry for the address.
+func (s *MemoryStore) Delete(_ context.Context, address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, address)
+	return nil
+}
@@ -0,0 +1,72 @@
+package suppression
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/pilinux/webhook/internal/sqlite"
+)
+
+// SQLiteStore stores suppressed addresses in a SQLite table named suppressions.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore creates a new SQLite store and the suppressions table if it does not exist.
+func NewSQLiteStore(ctx context.Context, db *sql.DB) (*SQLiteStore, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS suppressions (
+		address TEXT PRIMARY KEY,
+		reason TEXT NOT NULL,
+		detail TEXT NOT NULL,
+		email_id TEXT NOT NULL,
+		suppressed_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Put stores the entry, replacing an existing entry for the same address.
+func (s *SQLiteStore) Put(ctx context.Context, entry Entry) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO suppressions (address, reason, detail, email_id, suppressed_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		entry.Address, string(entry.Reason), entry.Detail, entry.EmailID,
+		sqlite.FormatTime(entry.SuppressedAt),
+	)
+	return err
+}
+
+// Get returns the entry for the address.
+func (s *SQLiteStore) Get(ctx context.Context, address string) (Entry, bool, error) {
+	var (
+		entry        Entry
+		reason       string
+		suppressedAt string
+	)
+	err := s.db.QueryRowContext(ctx,
+		`SELECT address, reason, detail, email_id, suppressed_at FROM suppressions WHERE address = ?`,
+		address,
+	).Scan(&entry.Address, &reason, &entry.Detail, &entry.EmailID, &suppressedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	entry.Reason = Reason(reason)
+	entry.SuppressedAt, err = sqlite.ParseTime(suppressedAt)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// Delete removes the entry for the address.
+func (s *SQLiteStore) Delete(ctx context.Context, address string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM suppressions WHERE address = ?`, address)
+	return err
+}
@@ -0,0 +1,179 @@
+// Package suppression maintains an email suppression list from resend webhook events.
+//
+// Hard bounces, spam complaints and unsubscribed contacts are added to the list,
+// so that the send path can skip addresses that would hurt the sender reputation.
+package suppression
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+)
+
+// Reason explains why an address is suppressed.
+type Reason string
+
+// all suppression reasons
+const (
+	// ReasonBounced - a permanent (hard) bounce
+	ReasonBounced Reason = "bounced"
+	// ReasonComplained - the recipient marked an email as spam
+	ReasonComplained Reason = "complained"
+	// ReasonUnsubscribed - the contact unsubscribed
+	ReasonUnsubscribed Reason = "unsubscribed"
+)
+
+// Entry is a suppressed address.
+type Entry struct {
+	Address string `json:"address"`
+	Reason  Reason `json:"reason"`
+	// Detail holds the bounce message, if any
+	Detail string `json:"detail,omitempty"`
+	// EmailID is the email that caused a bounce or complaint
+	EmailID      string    `json:"email_id,omitempty"`
+	SuppressedAt time.Time `json:"suppressed_at"`
+}
+
+// Store keeps suppressed addresses by their normalized address.
+type Store interface {
+	// Put stores the entry, replacing an existing entry for the same address
+	Put(ctx context.Context, entry Entry) error
+	// Get returns the entry for the address, ok is false if the address is not suppressed
+	Get(ctx context.Context, address string) (entry Entry, ok bool, err error)
+	// Delete removes the entry for the address
+	Delete(ctx context.Context, address string) error
+}
+
+// List is a suppression list maintained from resend payloads.
+type List struct {
+	store Store
+	// serializes read-modify-write cycles on the store
+	mu sync.Mutex
+}
+
+// New creates a new suppression list backed by the given store.
+func New(store Store) *List {
+	return &List{store: store}
+}
+
+// Apply updates the list from a payload:
+//
+//   - `email.bounced` with a permanent bounce suppresses all recipients
+//   - `email.complained` suppresses all recipients
+//   - `contact.updated` suppresses an unsubscribed contact and
+//     lifts the suppression of a resubscribed contact
+//
+// Other payloads are ignored.
+func (l *List) Apply(ctx context.Context, payload resend.Payload) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch payload.Type {
+	case resend.EmailBounced:
+		if !payload.Data.Bounce.IsHard() {
+			return nil
+		}
+		return l.suppressRecipients(ctx, payload, ReasonBounced, payload.Data.Bounce.Message)
+
+	case resend.EmailComplained:
+		return l.suppressRecipients(ctx, payload, ReasonComplained, "")
+
+	case resend.ContactUpdated:
+		address := Normalize(payload.Data.Email)
+		if address == "" {
+			return nil
+		}
+		entry, ok, err := l.store.Get(ctx, address)
+		if err != nil {
+			return err
+		}
+		// never replace or lift a bounce or complaint
+		if ok && entry.Reason != ReasonUnsubscribed {
+			return nil
+		}
+		// ignore updates older than the stored unsubscription
+		if ok && payload.CreatedAt.Before(entry.SuppressedAt) {
+			return nil
+		}
+
+		if payload.Data.Unsubscribed {
+			return l.store.Put(ctx, Entry{
+				Address:      address,
+				Reason:       ReasonUnsubscribed,
+				SuppressedAt: payload.CreatedAt.Time,
+			})
+		}
+		if !ok {
+			return nil
+		}
+		return l.store.Delete(ctx, address)
+	}
+	return nil
+}
+
+// Middleware applies every payload to the list before passing it on,
+// e.g. router.Use(list.Middleware()).
+func (l *List) Middleware() resend.Middleware {
+	return func(next resend.HandlerFunc) resend.HandlerFunc {
+		return func(ctx context.Context, payload resend.Payload) error {
+			if err := l.Apply(ctx, payload); err != nil {
+				return err
+			}
+			return next(ctx, payload)
+		}
+	}
+}
+
+// IsSuppressed reports whether the address is suppressed and returns
+// the reason and time of the suppression.
+func (l *List) IsSuppressed(ctx context.Context, address string) (Entry, bool, error) {
+	return l.store.Get(ctx, Normalize(address))
+}
+
+// Suppress adds the address to the list manually.
+func (l *List) Suppress(ctx context.Context, address string, reason Reason) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.store.Put(ctx, Entry{
+		Address:      Normalize(address),
+		Reason:       reason,
+		SuppressedAt: time.Now().UTC(),
+	})
+}
+
+// Remove lifts the suppression of the address.
+func (l *List) Remove(ctx context.Context, address string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.store.Delete(ctx, Normalize(address))
+}
+
+func (l *List) suppressRecipients(ctx context.Context, payload resend.Payload, reason Reason, detail string) error {
+	for _, to := range payload.Data.To {
+		address := Normalize(to)
+		if address == "" {
+			continue
+		}
+		err := l.store.Put(ctx, Entry{
+			Address:      address,
+			Reason:       reason,
+			Detail:       detail,
+			EmailID:      payload.Data.EmailID,
+			SuppressedAt: payload.CreatedAt.Time,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Normalize returns the lowercase bare address of `Name <user@example.com>`
+// or `User@Example.com`.
+func Normalize(address string) string {
+	return resend.NormalizeAddress(address)
+}
@@ -0,0 +1,121 @@
+package suppression
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+)
+
+func payload(t *testing.T, body string) resend.Payload {
+	t.Helper()
+	var p resend.Payload
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestApplyKeepsBounceOnUnsubscribeAndResubscribe(t *testing.T) {
+	ctx := context.Background()
+	l := New(NewMemoryStore())
+
+	events := []string{
+		`{"type":"email.bounced","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","to":["User@Example.com"],"bounce":{"type":"Permanent","message":"no such user"}}}`,
+		`{"type":"contact.updated","created_at":"2024-11-22T11:00:00Z","data":{"email":"user@example.com","unsubscribed":true}}`,
+		`{"type":"contact.updated","created_at":"2024-11-22T12:00:00Z","data":{"email":"user@example.com","unsubscribed":false}}`,
+	}
+	for _, e := range events {
+		if err := l.Apply(ctx, payload(t, e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry, ok, err := l.IsSuppressed(ctx, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || entry.Reason != ReasonBounced {
+		t.Fatalf("got %+v, %v, want bounced entry", entry, ok)
+	}
+}
+
+func TestApplyIgnoresStaleUnsubscribe(t *testing.T) {
+	ctx := context.Background()
+	l := New(NewMemoryStore())
+
+	events := []string{
+		`{"type":"contact.updated","created_at":"2024-11-22T14:00:00Z","data":{"email":"user@example.com","unsubscribed":true}}`,
+		// delivered out of order
+		`{"type":"contact.updated","created_at":"2024-11-22T13:30:00Z","data":{"email":"user@example.com","unsubscribed":true}}`,
+	}
+	for _, e := range events {
+		if err := l.Apply(ctx, payload(t, e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry, ok, err := l.IsSuppressed(ctx, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := payload(t, events[0]).CreatedAt.Time
+	if !ok || !entry.SuppressedAt.Equal(want) {
+		t.Fatalf("got %+v, %v, want unsubscription at %s", entry, ok, want)
+	}
+}
+
+// blockingStore pauses after reading an entry in Get until release is closed.
+type blockingStore struct {
+	*MemoryStore
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Get(ctx context.Context, address string) (Entry, bool, error) {
+	entry, ok, err := s.MemoryStore.Get(ctx, address)
+	s.entered <- struct{}{}
+	<-s.release
+	return entry, ok, err
+}
+
+func TestApplyKeepsConcurrentBounceOnResubscribe(t *testing.T) {
+	ctx := context.Background()
+	store := &blockingStore{MemoryStore: NewMemoryStore(), entered: make(chan struct{}), release: make(chan struct{})}
+	l := New(store)
+
+	if err := store.Put(ctx, Entry{Address: "user@example.com", Reason: ReasonUnsubscribed, SuppressedAt: time.Date(2024, 11, 22, 10, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+
+	resubscribed := make(chan error, 1)
+	go func() {
+		resubscribed <- l.Apply(ctx, payload(t, `{"type":"contact.updated","created_at":"2024-11-22T12:00:00Z","data":{"email":"user@example.com","unsubscribed":false}}`))
+	}()
+	<-store.entered
+
+	// the bounce arrives while the resubscription is between Get and Delete
+	bounced := make(chan error, 1)
+	go func() {
+		bounced <- l.Apply(ctx, payload(t, `{"type":"email.bounced","created_at":"2024-11-22T12:00:01Z","data":{"email_id":"e1","to":["user@example.com"],"bounce":{"type":"Permanent"}}}`))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(store.release)
+
+	if err := <-resubscribed; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-bounced; err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok, err := store.MemoryStore.Get(ctx, "user@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || entry.Reason != ReasonBounced {
+		t.Errorf("got %+v, %v, want the bounce to survive the resubscription", entry, ok)
+	}
+}
@@ -0,0 +1,64 @@
+package resend
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timeLayouts are the timestamp formats found in resend payloads,
+// e.g. `2024-11-22T23:41:12.126Z` and `2024-11-22 23:41:12.126+00:00`.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+}
+
+// Time is a timestamp in a resend payload.
+// An empty string or null decodes to the zero time.
+type Time struct {
+	time.Time
+}
+
+// ParseTime parses a timestamp in any of the formats used by resend.
+func ParseTime(s string) (Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Time{Time: t}, nil
+		}
+	}
+	return Time{}, fmt.Errorf("invalid timestamp: %q", s)
+}
+
+// UnmarshalJSON decodes a timestamp string.
+func (t *Time) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*t = Time{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*t = Time{}
+		return nil
+	}
+
+	parsed, err := ParseTime(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON encodes the timestamp in RFC 3339 format, or null for the zero time.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Format(time.RFC3339Nano))
+}
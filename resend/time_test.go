@@ -0,0 +1,81 @@
+package resend
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"2024-11-22T23:41:12.126Z", time.Date(2024, 11, 22, 23, 41, 12, 126000000, time.UTC)},
+		{"2024-11-22T23:41:12Z", time.Date(2024, 11, 22, 23, 41, 12, 0, time.UTC)},
+		{"2024-11-22T23:41:12.126+01:00", time.Date(2024, 11, 22, 22, 41, 12, 126000000, time.UTC)},
+		{"2024-11-22 23:41:12.126+00:00", time.Date(2024, 11, 22, 23, 41, 12, 126000000, time.UTC)},
+		{"2024-11-22 23:41:12+00:00", time.Date(2024, 11, 22, 23, 41, 12, 0, time.UTC)},
+		// Z07 offsets without minutes
+		{"2024-11-22T23:41:12.126+02", time.Date(2024, 11, 22, 21, 41, 12, 126000000, time.UTC)},
+		{"2024-11-22 23:41:12.126+00", time.Date(2024, 11, 22, 23, 41, 12, 126000000, time.UTC)},
+		{"2024-11-22 23:41:12-05", time.Date(2024, 11, 23, 4, 41, 12, 0, time.UTC)},
+		{"2024-11-22 23:41:12Z", time.Date(2024, 11, 22, 23, 41, 12, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "tomorrow", "2024-11-22", "22/11/2024 23:41", "2024-11-22T23:41:12"} {
+		if _, err := ParseTime(s); err == nil {
+			t.Errorf("%q: got nil error", s)
+		}
+	}
+}
+
+func TestTimeJSON(t *testing.T) {
+	for _, body := range []string{
+		`{"type":"email.sent","created_at":null}`,
+		`{"type":"email.sent","created_at":""}`,
+		`{"type":"email.sent"}`,
+	} {
+		var p Payload
+		if err := json.Unmarshal([]byte(body), &p); err != nil {
+			t.Errorf("%s: %v", body, err)
+			continue
+		}
+		if !p.CreatedAt.IsZero() {
+			t.Errorf("%s: got %s, want zero time", body, p.CreatedAt)
+		}
+	}
+
+	for _, body := range []string{
+		`{"type":"email.sent","created_at":"yesterday"}`,
+		`{"type":"email.sent","created_at":1732318872}`,
+		`{"type":"email.sent","created_at":"2024-11-22T23:41:12Z","data":{"created_at":"2024-13-01T00:00:00Z"}}`,
+	} {
+		var p Payload
+		if err := json.Unmarshal([]byte(body), &p); err == nil {
+			t.Errorf("%s: got nil error", body)
+		}
+	}
+
+	// zero times marshal as null, others in RFC 3339
+	b, err := json.Marshal(struct {
+		Zero Time `json:"zero"`
+		Set  Time `json:"set"`
+	}{Set: Time{Time: time.Date(2024, 11, 22, 23, 41, 12, 126000000, time.UTC)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"zero":null,"set":"2024-11-22T23:41:12.126Z"}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
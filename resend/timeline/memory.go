@@ -0,0 +1,68 @@
+package timeline
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pilinux/webhook/resend"
+)
+
+// MemoryStore is an in-memory Store safe for concurrent use.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	timelines  map[string]Timeline
+	recipients map[string]map[string]struct{}
+}
+
+// NewMemoryStore creates a new empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		timelines:  make(map[string]Timeline),
+		recipients: make(map[string]map[string]struct{}),
+	}
+}
+
+// Get returns the timeline of the email.
+func (s *MemoryStore) Get(_ context.Context, emailID string) (Timeline, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.timelines[emailID]
+	return clone(t), ok, nil
+}
+
+// Put stores the timeline and indexes it by its recipients.
+func (s *MemoryStore) Put(_ context.Context, t Timeline) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timelines[t.EmailID] = clone(t)
+	for _, to := range t.To {
+		address := resend.NormalizeAddress(to)
+		if s.recipients[address] == nil {
+			s.recipients[address] = make(map[string]struct{})
+		}
+		s.recipients[address][t.EmailID] = struct{}{}
+	}
+	return nil
+}
+
+// FindByRecipient returns the timelines of all emails sent to the address.
+func (s *MemoryStore) FindByRecipient(_ context.Context, address string) ([]Timeline, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var timelines []Timeline
+	for emailID := range s.recipients[address] {
+		timelines = append(timelines, clone(s.timelines[emailID]))
+	}
+	return timelines, nil
+}
+
+// clone copies the slices of a timeline so that callers cannot modify the stored version.
+func clone(t Timeline) Timeline {
+	t.To = append([]string(nil), t.To...)
+	t.Links = append([]string(nil), t.Links...)
+	t.Events = append([]Event(nil), t.Events...)
+	return t
+}
@@ -0,0 +1,242 @@
+// Package timeline aggregates resend email events into a delivery timeline per email,
+// to answer "what happened to email X?".
+package timeline
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook/resend"
+)
+
+// Status is the current state of an email.
+type Status string
+
+// all statuses, from the least to the most significant
+const (
+	StatusSent            Status = "sent"
+	StatusDeliveryDelayed Status = "delivery_delayed"
+	StatusDelivered       Status = "delivered"
+	StatusOpened          Status = "opened"
+	StatusClicked         Status = "clicked"
+	StatusBounced         Status = "bounced"
+	StatusComplained      Status = "complained"
+)
+
+// statuses maps the aggregated event types to the status they lead to.
+var statuses = map[resend.EventType]Status{
+	resend.EmailSent:            StatusSent,
+	resend.EmailDeliveryDelayed: StatusDeliveryDelayed,
+	resend.EmailDelivered:       StatusDelivered,
+	resend.EmailOpened:          StatusOpened,
+	resend.EmailClicked:         StatusClicked,
+	resend.EmailBounced:         StatusBounced,
+	resend.EmailComplained:      StatusComplained,
+}
+
+// rank orders the statuses so that events arriving out of order
+// never move an email back to an earlier status.
+var rank = map[Status]int{
+	StatusSent:            1,
+	StatusDeliveryDelayed: 2,
+	StatusDelivered:       4,
+	StatusOpened:          5,
+	StatusClicked:         6,
+	StatusBounced:         7,
+	StatusComplained:      8,
+}
+
+// softBounceRank is the rank of a transient or undetermined bounce: the email
+// may still be delivered on a later attempt, only a permanent bounce is final.
+const softBounceRank = 3
+
+// statusRank returns the rank of the status, taking the kind of bounce into account.
+func statusRank(status Status, bounce *resend.Bounce) int {
+	if status == StatusBounced && !bounce.IsHard() {
+		return softBounceRank
+	}
+	return rank[status]
+}
+
+// Event is a single entry of a timeline.
+type Event struct {
+	Type resend.EventType `json:"type"`
+	At   time.Time        `json:"at"`
+	// Link is the clicked link of `email.clicked` events
+	Link string `json:"link,omitempty"`
+}
+
+// Timeline is the delivery history of a single email.
+type Timeline struct {
+	EmailID string   `json:"email_id"`
+	From    string   `json:"from,omitempty"`
+	To      []string `json:"to,omitempty"`
+	Subject string   `json:"subject,omitempty"`
+	Status  Status   `json:"status"`
+
+	// the times are zero until the first such event is applied
+	SentAt         time.Time `json:"sent_at"`
+	DeliveredAt    time.Time `json:"delivered_at"`
+	FirstOpenedAt  time.Time `json:"first_opened_at"`
+	FirstClickedAt time.Time `json:"first_clicked_at"`
+	Opens          int       `json:"opens"`
+	Clicks         int       `json:"clicks"`
+	// Links lists the distinct clicked links in the order they were first clicked
+	Links []string `json:"links,omitempty"`
+	// Bounce holds the details of a bounce
+	Bounce *resend.Bounce `json:"bounce,omitempty"`
+
+	// Events lists all aggregated events, oldest first
+	Events []Event `json:"events"`
+}
+
+// Store keeps timelines by email ID.
+type Store interface {
+	// Get returns the timeline of the email, ok is false if there is none
+	Get(ctx context.Context, emailID string) (timeline Timeline, ok bool, err error)
+	// Put stores the timeline, replacing the previous version
+	Put(ctx context.Context, timeline Timeline) error
+	// FindByRecipient returns the timelines of all emails sent to the normalized address
+	FindByRecipient(ctx context.Context, address string) ([]Timeline, error)
+}
+
+// Aggregator folds resend payloads into timelines.
+type Aggregator struct {
+	store Store
+	// serializes read-modify-write cycles on the store
+	mu sync.Mutex
+}
+
+// NewAggregator creates a new aggregator backed by the given store.
+func NewAggregator(store Store) *Aggregator {
+	return &Aggregator{store: store}
+}
+
+// Apply adds an email event to the timeline of its email.
+// Payloads of other event types and redelivered events are ignored.
+func (a *Aggregator) Apply(ctx context.Context, payload resend.Payload) error {
+	status, ok := statuses[payload.Type]
+	if !ok || payload.Data.EmailID == "" {
+		return nil
+	}
+
+	event := Event{
+		Type: payload.Type,
+		At:   payload.CreatedAt.Time,
+	}
+	if payload.Data.Open != nil && !payload.Data.Open.Timestamp.IsZero() {
+		event.At = payload.Data.Open.Timestamp.Time
+	}
+	if payload.Data.Click != nil {
+		event.Link = payload.Data.Click.Link
+		if !payload.Data.Click.Timestamp.IsZero() {
+			event.At = payload.Data.Click.Timestamp.Time
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	t, _, err := a.store.Get(ctx, payload.Data.EmailID)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range t.Events {
+		if e.Type == event.Type && e.At.Equal(event.At) && e.Link == event.Link {
+			// redelivered event
+			return nil
+		}
+	}
+
+	t.EmailID = payload.Data.EmailID
+	if payload.Data.From != "" {
+		t.From = payload.Data.From
+	}
+	if len(payload.Data.To) > 0 {
+		t.To = payload.Data.To
+	}
+	if payload.Data.Subject != "" {
+		t.Subject = payload.Data.Subject
+	}
+	if statusRank(status, payload.Data.Bounce) > statusRank(t.Status, t.Bounce) {
+		t.Status = status
+	}
+
+	switch payload.Type {
+	case resend.EmailSent:
+		t.SentAt = earliest(t.SentAt, event.At)
+	case resend.EmailDelivered:
+		t.DeliveredAt = earliest(t.DeliveredAt, event.At)
+	case resend.EmailOpened:
+		t.Opens++
+		t.FirstOpenedAt = earliest(t.FirstOpenedAt, event.At)
+	case resend.EmailClicked:
+		t.Clicks++
+		t.FirstClickedAt = earliest(t.FirstClickedAt, event.At)
+		if event.Link != "" && !contains(t.Links, event.Link) {
+			t.Links = append(t.Links, event.Link)
+		}
+	case resend.EmailBounced:
+		// a permanent bounce is kept over later soft bounces
+		if !t.Bounce.IsHard() {
+			t.Bounce = payload.Data.Bounce
+		}
+	}
+
+	t.Events = append(t.Events, event)
+	sort.SliceStable(t.Events, func(i, j int) bool {
+		return t.Events[i].At.Before(t.Events[j].At)
+	})
+
+	return a.store.Put(ctx, t)
+}
+
+// Middleware applies every payload to the aggregator before passing it on,
+// e.g. router.Use(aggregator.Middleware()).
+func (a *Aggregator) Middleware() resend.Middleware {
+	return func(next resend.HandlerFunc) resend.HandlerFunc {
+		return func(ctx context.Context, payload resend.Payload) error {
+			if err := a.Apply(ctx, payload); err != nil {
+				return err
+			}
+			return next(ctx, payload)
+		}
+	}
+}
+
+// Get returns the timeline of the email with the given ID.
+func (a *Aggregator) Get(ctx context.Context, emailID string) (Timeline, bool, error) {
+	return a.store.Get(ctx, emailID)
+}
+
+// ByRecipient returns the timelines of all emails sent to the address, newest first.
+func (a *Aggregator) ByRecipient(ctx context.Context, address string) ([]Timeline, error) {
+	timelines, err := a.store.FindByRecipient(ctx, resend.NormalizeAddress(address))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(timelines, func(i, j int) bool {
+		return timelines[i].SentAt.After(timelines[j].SentAt)
+	})
+	return timelines, nil
+}
+
+func earliest(current, t time.Time) time.Time {
+	if current.IsZero() || t.Before(current) {
+		return t
+	}
+	return current
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
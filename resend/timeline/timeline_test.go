@@ -0,0 +1,74 @@
+package timeline
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pilinux/webhook/resend"
+)
+
+func payload(t *testing.T, body string) resend.Payload {
+	t.Helper()
+	var p resend.Payload
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func apply(t *testing.T, a *Aggregator, events ...string) Timeline {
+	t.Helper()
+	ctx := context.Background()
+	for _, e := range events {
+		if err := a.Apply(ctx, payload(t, e)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tl, ok, err := a.Get(ctx, "e1")
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	return tl
+}
+
+func TestApplyStatus(t *testing.T) {
+	const (
+		sent       = `{"type":"email.sent","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","to":["User <User@Example.com>"]}}`
+		softBounce = `{"type":"email.bounced","created_at":"2024-11-22T10:01:00Z","data":{"email_id":"e1","bounce":{"type":"Transient","subType":"MailboxFull"}}}`
+		delivered  = `{"type":"email.delivered","created_at":"2024-11-22T10:30:00Z","data":{"email_id":"e1"}}`
+		hardBounce = `{"type":"email.bounced","created_at":"2024-11-22T10:31:00Z","data":{"email_id":"e1","bounce":{"type":"Permanent","subType":"General"}}}`
+		opened     = `{"type":"email.opened","created_at":"2024-11-22T11:00:00Z","data":{"email_id":"e1"}}`
+	)
+
+	tests := []struct {
+		name   string
+		events []string
+		want   Status
+	}{
+		{"soft bounce", []string{sent, softBounce}, StatusBounced},
+		{"delivered after soft bounce", []string{sent, softBounce, delivered}, StatusDelivered},
+		{"soft bounce out of order", []string{sent, delivered, softBounce}, StatusDelivered},
+		{"hard bounce is final", []string{sent, hardBounce, opened}, StatusBounced},
+		{"soft bounce after hard bounce", []string{sent, hardBounce, softBounce, delivered}, StatusBounced},
+	}
+	for _, tt := range tests {
+		tl := apply(t, NewAggregator(NewMemoryStore()), tt.events...)
+		if tl.Status != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, tl.Status, tt.want)
+		}
+	}
+}
+
+func TestByRecipientNormalizesAddress(t *testing.T) {
+	a := NewAggregator(NewMemoryStore())
+	apply(t, a, `{"type":"email.sent","created_at":"2024-11-22T10:00:00Z","data":{"email_id":"e1","to":["User <User@Example.com>"]}}`)
+
+	timelines, err := a.ByRecipient(context.Background(), " USER@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timelines) != 1 || timelines[0].EmailID != "e1" {
+		t.Errorf("got %+v, want the timeline of e1", timelines)
+	}
+}
@@ -4,30 +4,104 @@
 package resend
 
 import (
-	"encoding/json"
-	"io"
+	"context"
 	"net/http"
 
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/standardwebhooks"
 	"github.com/pilinux/webhook/svixgo"
-	svix "github.com/svix/svix-webhooks/go"
 )
 
+// DefaultMaxBodyBytes is the default maximum size of a request body (64KB).
+const DefaultMaxBodyBytes = webhook.DefaultMaxBodyBytes
+
+// Option configures HandleRequest, Handler and Provider.
+type Option = standardwebhooks.Option
+
+// WithMaxBodyBytes sets the maximum size of a request body.
+func WithMaxBodyBytes(n int64) Option {
+	return standardwebhooks.WithMaxBodyBytes(n)
+}
+
+// WithWebhookOptions sets the options of the webhook instances created from a
+// webhook.SecretProvider, e.g. svixgo.WithNonceStore.
+func WithWebhookOptions(opts ...svixgo.Option) Option {
+	return standardwebhooks.WithWebhookOptions(opts...)
+}
+
 // HandleRequest validates the incoming payload against the svix signature headers
 // using the webhook signing secret and binds the raw data to a payload struct.
-func HandleRequest(r *http.Request, wh *svix.Webhook) (payload Payload, err error) {
-	headers := r.Header
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		return
-	}
+//
+// The returned status code is meant to be sent back to resend when err is not nil.
+//
+// Replay protection is enabled by creating wh with svixgo.WithNonceStore.
+// A replayed message returns svixgo.ErrReplayed with 200 OK: resend retries every
+// non-2xx response with the same message ID, so a replay is acknowledged and
+// must not be processed again. The message ID is recorded before the payload is
+// returned; if processing the payload fails, call wh.Forget(ctx, svixgo.MessageID(r.Header))
+// before responding with an error, otherwise every retry is rejected as a replay.
+func HandleRequest(w http.ResponseWriter, r *http.Request, wh *svixgo.Webhook, opts ...Option) (Payload, int, error) {
+	msg, statusCode, err := standardwebhooks.HandleRequest[Payload](w, r, wh, opts...)
+	return msg.Payload, statusCode, err
+}
+
+// HandleRequestWithSecret is HandleRequest with a webhook instance created from the
+// signing secret read from sp for every request, so that the secret can rotate without a restart.
+// Use WithWebhookOptions to configure the webhook instance.
+func HandleRequestWithSecret(w http.ResponseWriter, r *http.Request, sp webhook.SecretProvider, opts ...Option) (Payload, int, error) {
+	msg, statusCode, err := standardwebhooks.HandleRequestWithSecret[Payload](w, r, sp, opts...)
+	return msg.Payload, statusCode, err
+}
 
-	// validate the data
-	err = svixgo.Verify(wh, body, headers)
-	if err != nil {
-		return
+// HandlerFunc processes a verified payload.
+type HandlerFunc func(ctx context.Context, payload Payload) error
+
+// Handler is an http.Handler that verifies incoming requests with HandleRequest
+// and dispatches the payload with its Router.
+// It is a standardwebhooks.Handler of Payload with the resend specific Router.
+//
+// Payloads without a callback are acknowledged with 200 OK. If a callback
+// returns an error, 500 Internal Server Error is sent so that resend retries the delivery.
+type Handler struct {
+	*Router
+
+	handler *standardwebhooks.Handler[Payload]
+}
+
+// NewHandler creates a new handler for the given webhook instance with an empty router.
+// Register callbacks directly on the handler, e.g. h.OnEmailBounced(fn),
+// or replace h.Router with a shared router.
+func NewHandler(wh *svixgo.Webhook, opts ...Option) *Handler {
+	return newHandler(standardwebhooks.NewHandler[Payload](wh, opts...))
+}
+
+// NewHandlerWithSecret creates a new handler that creates the webhook instance from the
+// signing secret read from sp for every request, so that the secret can rotate without a restart.
+// Use WithWebhookOptions to configure the webhook instance.
+func NewHandlerWithSecret(sp webhook.SecretProvider, opts ...Option) *Handler {
+	return newHandler(standardwebhooks.NewHandlerWithSecret[Payload](sp, opts...))
+}
+
+func newHandler(handler *standardwebhooks.Handler[Payload]) *Handler {
+	h := &Handler{
+		Router:  NewRouter(),
+		handler: handler,
 	}
+	// every message goes through h.Router, which may be replaced after creation
+	handler.Fallback(func(ctx context.Context, msg standardwebhooks.Message[Payload]) error {
+		return h.Dispatch(ctx, msg.Payload)
+	})
+	return h
+}
+
+// OnError sets a function that is called with every rejected request
+// and every callback error, e.g. for logging.
+func (h *Handler) OnError(fn func(r *http.Request, err error)) *Handler {
+	h.handler.OnError(fn)
+	return h
+}
 
-	// bind raw data to payload struct
-	err = json.Unmarshal(body, &payload)
-	return
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
 }
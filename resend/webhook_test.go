@@ -0,0 +1,91 @@
+package resend
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/svixgo"
+)
+
+const testSecret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+
+func signedRequest(t *testing.T, msgID, payload string) *http.Request {
+	t.Helper()
+	r, err := svixgo.NewSignedRequest(context.Background(), testSecret, "/webhook/resend", msgID, time.Now(), []byte(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestHandlerDispatch(t *testing.T) {
+	var got []string
+	router := NewRouter().
+		Use(func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, payload Payload) error {
+				got = append(got, "middleware "+string(payload.Type))
+				return next(ctx, payload)
+			}
+		}).
+		OnEmailBounced(func(_ context.Context, payload Payload) error {
+			got = append(got, "bounced "+payload.Data.EmailID)
+			return nil
+		}).
+		Fallback(func(_ context.Context, payload Payload) error {
+			return errors.New("unexpected " + string(payload.Type))
+		})
+
+	// both constructors dispatch with a shared router
+	wh, err := svixgo.NewWebhook(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []*Handler{NewHandler(wh), NewHandlerWithSecret(webhook.StaticSecret(testSecret))} {
+		h.Router = router
+		var errs []error
+		h.OnError(func(_ *http.Request, err error) { errs = append(errs, err) })
+
+		got = nil
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, signedRequest(t, "msg_1", `{"type":"email.bounced","data":{"email_id":"e1"}}`))
+		if w.Code != http.StatusOK || len(got) != 2 || got[0] != "middleware email.bounced" || got[1] != "bounced e1" {
+			t.Errorf("got status %d and calls %v", w.Code, got)
+		}
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, signedRequest(t, "msg_2", `{"type":"email.sent","data":{"email_id":"e1"}}`))
+		if w.Code != http.StatusInternalServerError || len(errs) != 1 || errs[0].Error() != "email.sent: unexpected email.sent" {
+			t.Errorf("got status %d and errors %v", w.Code, errs)
+		}
+	}
+}
+
+func TestHandlerRejects(t *testing.T) {
+	h := NewHandlerWithSecret(webhook.StaticSecret(testSecret), WithMaxBodyBytes(64),
+		WithWebhookOptions(svixgo.WithNonceStore(svixgo.NewMemoryNonceStore(10))))
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		want int
+	}{
+		{"valid", signedRequest(t, "msg_1", `{"type":"email.sent"}`), http.StatusOK},
+		{"replay", signedRequest(t, "msg_1", `{"type":"email.sent"}`), http.StatusOK},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/webhook/resend", nil), http.StatusBadRequest},
+		{"method", httptest.NewRequest(http.MethodGet, "/webhook/resend", nil), http.StatusMethodNotAllowed},
+		{"too large", signedRequest(t, "msg_2", `{"type":"email.sent","data":{"subject":"`+strings.Repeat("x", 64)+`"}}`), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, tt.r)
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package retry
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Record is a message that could not be processed after all attempts.
+type Record struct {
+	ID       string      `json:"id"`
+	Provider string      `json:"provider,omitempty"`
+	Type     string      `json:"type,omitempty"`
+	Body     string      `json:"body"`
+	Headers  http.Header `json:"headers,omitempty"`
+	Error    string      `json:"error"`
+	Attempts []Attempt   `json:"attempts"`
+	FailedAt time.Time   `json:"failed_at"`
+}
+
+// Message converts the record back to the message it was created from.
+func (rec Record) Message() Message {
+	return Message{
+		ID:       rec.ID,
+		Provider: rec.Provider,
+		Type:     rec.Type,
+		Body:     []byte(rec.Body),
+		Headers:  rec.Headers,
+	}
+}
+
+// Sink stores dead-lettered messages for later inspection and re-drive.
+type Sink interface {
+	// Put stores the record, replacing an existing record with the same ID
+	Put(ctx context.Context, rec Record) error
+	// List returns all stored records, oldest first
+	List(ctx context.Context) ([]Record, error)
+	// Delete removes the record with the given ID
+	Delete(ctx context.Context, id string) error
+}
+
+// Redrive passes every record in the sink to fn and removes the records
+// that were processed successfully. It returns the number of re-driven records
+// and the first error returned by fn or the sink.
+func Redrive(ctx context.Context, sink Sink, fn func(ctx context.Context, rec Record) error) (n int, err error) {
+	records, err := sink.List(ctx)
+	if err != nil {
+		return
+	}
+
+	var fnErr error
+	for _, rec := range records {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if e := fn(ctx, rec); e != nil {
+			if fnErr == nil {
+				fnErr = e
+			}
+			continue
+		}
+		if err = sink.Delete(ctx, rec.ID); err != nil {
+			return
+		}
+		n++
+	}
+	err = fnErr
+	return
+}
@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	s := NewFileSink(path)
+
+	// a missing file has no records
+	if records, err := s.List(ctx); err != nil || len(records) != 0 {
+		t.Fatalf("got %+v, %v before the first write", records, err)
+	}
+
+	for _, rec := range []Record{
+		{ID: "msg_1", Error: "first"},
+		{ID: "msg_2", Error: "second"},
+		{ID: "msg_1", Error: "again"},
+	} {
+		if err := s.Put(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := s.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].ID != "msg_1" || records[0].Error != "again" || records[1].ID != "msg_2" {
+		t.Fatalf("got %+v, want the latest version of msg_1 and msg_2", records)
+	}
+
+	// Delete rewrites the file without the record and its older versions
+	if err = s.Delete(ctx, "msg_1"); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"id":"msg_2"`) {
+		t.Errorf("file after delete:\n%s", b)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want the temporary file to be removed", len(entries))
+	}
+
+	// appending after a rewrite keeps the remaining record
+	if err = s.Put(ctx, Record{ID: "msg_3"}); err != nil {
+		t.Fatal(err)
+	}
+	if records, err = s.List(ctx); err != nil || len(records) != 2 || records[1].ID != "msg_3" {
+		t.Errorf("got %+v, %v", records, err)
+	}
+}
+
+func TestFileSinkReportsCorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	if err := os.WriteFile(path, []byte("{\"id\":\"msg_1\"}\n\nnot json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewFileSink(path).List(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "dead.jsonl:3:") {
+		t.Errorf("got %v, want an error for line 3", err)
+	}
+}
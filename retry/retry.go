@@ -0,0 +1,173 @@
+// Package retry re-runs failed webhook handlers with exponential backoff and jitter
+// and hands events that never succeed over to a dead-letter sink.
+//
+// Webhooks are usually acknowledged before the handler runs, so a failing handler
+// would otherwise lose the event for good.
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Policy describes how often and how fast a failed handler is retried.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the second attempt, doubled after every failure
+	BaseDelay time.Duration
+	// MaxDelay caps a single delay, including jitter
+	MaxDelay time.Duration
+	// Jitter is the fraction (0 to 1) of each delay that is randomized
+	Jitter float64
+}
+
+// DefaultPolicy makes five attempts within roughly ten seconds.
+var DefaultPolicy = Policy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.2,
+}
+
+// Delay returns the time to wait after the given number of failed attempts.
+func (p Policy) Delay(failed int) time.Duration {
+	if failed < 1 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	d := p.BaseDelay
+	for i := 1; i < failed; i++ {
+		if d > math.MaxInt64/2 || (p.MaxDelay > 0 && d >= p.MaxDelay) {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		// spread the delay evenly over [d*(1-jitter), d*(1+jitter))
+		spread := float64(d) * jitter
+		d = time.Duration(float64(d) - spread + rand.Float64()*2*spread)
+		if p.MaxDelay > 0 && d > p.MaxDelay {
+			d = p.MaxDelay
+		}
+	}
+	return d
+}
+
+// Attempt records the outcome of a single handler invocation.
+type Attempt struct {
+	Number    int           `json:"number"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// HandlerFunc processes a single event.
+type HandlerFunc func(ctx context.Context) error
+
+// Do calls fn until it succeeds, the policy runs out of attempts or ctx is done.
+// It returns the history of all attempts and the last error,
+// which is ctx.Err() if ctx is done while waiting for the next attempt.
+func Do(ctx context.Context, p Policy, fn HandlerFunc) (attempts []Attempt, err error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for n := 1; n <= maxAttempts; n++ {
+		if n > 1 {
+			timer := time.NewTimer(p.Delay(n - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return attempts, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		start := time.Now()
+		err = fn(ctx)
+		attempt := Attempt{
+			Number:    n,
+			StartedAt: start,
+			Duration:  time.Since(start),
+		}
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		attempts = append(attempts, attempt)
+
+		if err == nil {
+			return
+		}
+	}
+	return
+}
+
+// Message is the received webhook a handler works on.
+// Body and Headers are kept so that a failed message can be re-driven later.
+type Message struct {
+	ID       string
+	Provider string
+	Type     string
+	Body     []byte
+	Headers  http.Header
+}
+
+// Runner retries handlers according to Policy and stores messages
+// that still fail after the last attempt in Sink.
+type Runner struct {
+	Policy Policy
+	Sink   Sink
+}
+
+// NewRunner creates a new runner with the given policy and dead-letter sink.
+func NewRunner(policy Policy, sink Sink) *Runner {
+	return &Runner{
+		Policy: policy,
+		Sink:   sink,
+	}
+}
+
+// Run processes the message with fn. When all attempts fail, the message
+// is written to the dead-letter sink together with its attempt history.
+// The error of Do is returned; a failure of the sink is returned instead if it occurs.
+func (r *Runner) Run(ctx context.Context, msg Message, fn HandlerFunc) error {
+	attempts, err := Do(ctx, r.Policy, fn)
+	if err == nil || r.Sink == nil {
+		return err
+	}
+
+	// record why the handler failed, not that ctx was done during the backoff
+	reason := err.Error()
+	if n := len(attempts); n > 0 {
+		reason = attempts[n-1].Error
+	}
+
+	record := Record{
+		ID:       msg.ID,
+		Provider: msg.Provider,
+		Type:     msg.Type,
+		Body:     string(msg.Body),
+		Headers:  msg.Headers,
+		Error:    reason,
+		Attempts: attempts,
+		FailedAt: time.Now().UTC(),
+	}
+	// the sink must work even if the handler context was canceled
+	if sinkErr := r.Sink.Put(context.WithoutCancel(ctx), record); sinkErr != nil {
+		return sinkErr
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		failed int
+		want   time.Duration
+	}{
+		{0, 0},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{100, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.Delay(tt.failed); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.failed, got, tt.want)
+		}
+	}
+
+	// no cap, no overflow
+	if got := (Policy{BaseDelay: time.Second}).Delay(1000); got <= 0 {
+		t.Errorf("uncapped Delay(1000) = %v, want > 0", got)
+	}
+}
+
+func TestPolicyDelayJitter(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, MaxDelay: 4 * time.Second, Jitter: 0.5}
+
+	for range 1000 {
+		if got := p.Delay(1); got < 500*time.Millisecond || got >= 1500*time.Millisecond {
+			t.Fatalf("Delay(1) = %v, want within [500ms, 1.5s)", got)
+		}
+		// jitter never pushes a delay beyond the cap
+		if got := p.Delay(3); got < 2*time.Second || got > 4*time.Second {
+			t.Fatalf("Delay(3) = %v, want within [2s, 4s]", got)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	p := Policy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	calls := 0
+	attempts, err := Do(ctx, p, func(context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	if err != nil || len(attempts) != 2 || attempts[0].Error != "unavailable" || attempts[1].Error != "" {
+		t.Errorf("got %+v, %v, want success on the second attempt", attempts, err)
+	}
+
+	failed := errors.New("failed")
+	attempts, err = Do(ctx, p, func(context.Context) error { return failed })
+	if !errors.Is(err, failed) || len(attempts) != 3 || attempts[2].Number != 3 {
+		t.Errorf("got %+v, %v, want three failed attempts", attempts, err)
+	}
+
+	// a zero policy makes a single attempt
+	calls = 0
+	if _, err = Do(ctx, Policy{}, func(context.Context) error { calls++; return failed }); calls != 1 {
+		t.Errorf("zero policy made %d attempts, want 1", calls)
+	}
+}
+
+func TestDoCanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := Policy{MaxAttempts: 3, BaseDelay: time.Hour}
+
+	attempts, err := Do(ctx, p, func(context.Context) error {
+		cancel()
+		return errors.New("failed")
+	})
+	if !errors.Is(err, context.Canceled) || len(attempts) != 1 {
+		t.Errorf("got %+v, %v, want one attempt and context.Canceled", attempts, err)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+	sink := NewFileSink(filepath.Join(t.TempDir(), "dead.jsonl"))
+	r := NewRunner(Policy{MaxAttempts: 2, BaseDelay: time.Millisecond}, sink)
+	msg := Message{ID: "msg_1", Provider: "resend", Type: "email.sent", Body: []byte(`{}`)}
+
+	if err := r.Run(ctx, msg, func(context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := sink.List(ctx); len(records) != 0 {
+		t.Fatalf("got %+v, want no dead letters after success", records)
+	}
+
+	failed := errors.New("database unavailable")
+	if err := r.Run(ctx, msg, func(context.Context) error { return failed }); !errors.Is(err, failed) {
+		t.Fatalf("Run = %v, want %v", err, failed)
+	}
+	records, err := sink.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].ID != "msg_1" || records[0].Error != "database unavailable" || len(records[0].Attempts) != 2 {
+		t.Fatalf("got %+v", records)
+	}
+	if got := records[0].Message(); got.ID != msg.ID || string(got.Body) != "{}" || got.Type != msg.Type {
+		t.Errorf("Message() = %+v, want %+v", got, msg)
+	}
+}
+
+func TestRunnerCanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := NewFileSink(filepath.Join(t.TempDir(), "dead.jsonl"))
+	r := NewRunner(Policy{MaxAttempts: 3, BaseDelay: time.Hour}, sink)
+
+	err := r.Run(ctx, Message{ID: "msg_1"}, func(context.Context) error {
+		cancel()
+		return errors.New("database unavailable")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+
+	// the sink is written despite the canceled context and keeps the handler error
+	records, err := sink.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Error != "database unavailable" {
+		t.Errorf("got %+v, want the handler error", records)
+	}
+}
+
+func TestRedrive(t *testing.T) {
+	ctx := context.Background()
+	sink := NewFileSink(filepath.Join(t.TempDir(), "dead.jsonl"))
+	for _, id := range []string{"msg_1", "msg_2", "msg_3"} {
+		if err := sink.Put(ctx, Record{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failed := errors.New("still failing")
+	var got []string
+	n, err := Redrive(ctx, sink, func(_ context.Context, rec Record) error {
+		got = append(got, rec.ID)
+		if rec.ID == "msg_2" {
+			return failed
+		}
+		return nil
+	})
+	if n != 2 || !errors.Is(err, failed) || len(got) != 3 {
+		t.Fatalf("got %d, %v after %v, want 2 re-driven and the handler error", n, err, got)
+	}
+
+	records, err := sink.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].ID != "msg_2" {
+		t.Errorf("got %+v, want only the failed record to remain", records)
+	}
+}
@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/pilinux/webhook/internal/sqlite"
+)
+
+// SQLiteSink stores dead-lettered messages in a SQLite table named dead_letters.
+type SQLiteSink struct {
+	db *sql.DB
+}
+
+// NewSQLiteSink creates a new SQLite sink and the dead_letters table if it does not exist.
+func NewSQLiteSink(ctx context.Context, db *sql.DB) (*SQLiteSink, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS dead_letters (
+		id TEXT PRIMARY KEY,
+		provider TEXT NOT NULL,
+		type TEXT NOT NULL,
+		body TEXT NOT NULL,
+		headers TEXT NOT NULL,
+		error TEXT NOT NULL,
+		attempts TEXT NOT NULL,
+		failed_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteSink{db: db}, nil
+}
+
+// Put stores the record, replacing an existing record with the same ID.
+func (s *SQLiteSink) Put(ctx context.Context, rec Record) error {
+	headers, err := json.Marshal(rec.Headers)
+	if err != nil {
+		return err
+	}
+	attempts, err := json.Marshal(rec.Attempts)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO dead_letters (id, provider, type, body, headers, error, attempts, failed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		rec.ID, rec.Provider, rec.Type, rec.Body, string(headers), rec.Error, string(attempts),
+		sqlite.FormatTime(rec.FailedAt),
+	)
+	return err
+}
+
+// List returns all stored records, oldest first.
+func (s *SQLiteSink) List(ctx context.Context) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, provider, type, body, headers, error, attempts, failed_at
+		FROM dead_letters ORDER BY failed_at, id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var (
+			rec      Record
+			headers  string
+			attempts string
+			failedAt string
+		)
+		err = rows.Scan(&rec.ID, &rec.Provider, &rec.Type, &rec.Body, &headers, &rec.Error, &attempts, &failedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(headers), &rec.Headers); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(attempts), &rec.Attempts); err != nil {
+			return nil, err
+		}
+		if rec.FailedAt, err = sqlite.ParseTime(failedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// Delete removes the record with the given ID.
+func (s *SQLiteSink) Delete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM dead_letters WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,134 @@
+// Package secret implements webhook.SecretProvider backends: environment variables,
+// files watched for changes and a Vault compatible HTTP API.
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook"
+)
+
+// DefaultInterval is the default interval of polling a file for changes.
+const DefaultInterval = 5 * time.Second
+
+var (
+	_ webhook.SecretProvider = Env("")
+	_ webhook.SecretProvider = (*File)(nil)
+	_ webhook.SecretProvider = (*Vault)(nil)
+)
+
+// Env is the name of an environment variable holding the secret.
+// It is read on every call, without surrounding whitespace.
+type Env string
+
+// Secret returns the value of the environment variable.
+func (e Env) Secret(context.Context) (string, error) {
+	value := strings.TrimSpace(os.Getenv(string(e)))
+	if value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", string(e))
+	}
+	return value, nil
+}
+
+// File holds the secret of a file, without surrounding whitespace, and reloads it
+// when the modification time or size of the file changes, e.g. when a mounted
+// Kubernetes secret or Docker secret is rotated.
+type File struct {
+	path string
+
+	mu      sync.RWMutex
+	value   string
+	modTime time.Time
+	size    int64
+	err     error
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewFile reads the secret file and polls it for changes every interval,
+// DefaultInterval if not positive. Call Close to stop polling.
+func NewFile(path string, interval time.Duration) (*File, error) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	f := &File{path: path, stop: make(chan struct{})}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-f.stop:
+				return
+			case <-ticker.C:
+				_ = f.reload()
+			}
+		}
+	}()
+	return f, nil
+}
+
+// Secret returns the current secret. If the last reload failed, e.g. while the file
+// is being replaced, the previous secret is returned.
+func (f *File) Secret(context.Context) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.value, nil
+}
+
+// Err returns the error of the last reload, nil if it succeeded.
+func (f *File) Err() error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.err
+}
+
+// Close stops polling the file.
+func (f *File) Close() error {
+	f.once.Do(func() { close(f.stop) })
+	return nil
+}
+
+// reload reads the file if it changed since the last read.
+func (f *File) reload() error {
+	info, err := os.Stat(f.path)
+	if err == nil {
+		f.mu.RLock()
+		unchanged := f.value != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size
+		f.mu.RUnlock()
+		if unchanged {
+			return nil
+		}
+	}
+
+	var value string
+	if err == nil {
+		var b []byte
+		if b, err = os.ReadFile(f.path); err == nil {
+			if value = strings.TrimSpace(string(b)); value == "" {
+				err = fmt.Errorf("secret file %s is empty", f.path)
+			}
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+	if err != nil {
+		return err
+	}
+	f.value = value
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return nil
+}
@@ -0,0 +1,110 @@
+package secret
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_TEST_SECRET", "  whsec_1\n")
+	if got, err := Env("WEBHOOK_TEST_SECRET").Secret(context.Background()); err != nil || got != "whsec_1" {
+		t.Errorf("got %q, %v, want whsec_1", got, err)
+	}
+
+	// a rotated value is read on the next call
+	t.Setenv("WEBHOOK_TEST_SECRET", "whsec_2")
+	if got, _ := Env("WEBHOOK_TEST_SECRET").Secret(context.Background()); got != "whsec_2" {
+		t.Errorf("got %q, want whsec_2", got)
+	}
+
+	t.Setenv("WEBHOOK_TEST_SECRET", " ")
+	if _, err := Env("WEBHOOK_TEST_SECRET").Secret(context.Background()); err == nil {
+		t.Error("got nil error for a blank variable")
+	}
+}
+
+// waitFor polls the secret of f until it equals want or a second passed.
+func waitFor(t *testing.T, f *File, want string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		got, err := f.Secret(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	write := func(value string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(value), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := NewFile(path, time.Millisecond); err == nil {
+		t.Fatal("got nil error for a missing file")
+	}
+	write("\n")
+	if _, err := NewFile(path, time.Millisecond); err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Fatalf("got %v, want an error for an empty file", err)
+	}
+
+	write("whsec_1\n")
+	f, err := NewFile(path, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	waitFor(t, f, "whsec_1")
+
+	// a rotated secret is picked up by polling
+	write("whsec_rotated\n")
+	waitFor(t, f, "whsec_rotated")
+	if err = f.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// while the file is empty or missing the previous secret is kept
+	write("")
+	deadline := time.Now().Add(time.Second)
+	for f.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err = f.Err(); err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Fatalf("Err() = %v, want an error for an empty file", err)
+	}
+	waitFor(t, f, "whsec_rotated")
+
+	if err = os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	deadline = time.Now().Add(time.Second)
+	for !os.IsNotExist(f.Err()) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err = f.Err(); !os.IsNotExist(err) {
+		t.Fatalf("Err() = %v, want a missing file error", err)
+	}
+	waitFor(t, f, "whsec_rotated")
+
+	// the reload succeeds again once the file is back
+	write("whsec_3")
+	waitFor(t, f, "whsec_3")
+	if err = f.Err(); err != nil {
+		t.Errorf("Err() = %v after the file was restored", err)
+	}
+}
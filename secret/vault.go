@@ -0,0 +1,179 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaults of Vault
+const (
+	// DefaultTTL is the default time a secret read from Vault is cached
+	DefaultTTL = time.Minute
+	// DefaultTimeout is the default timeout of a request to Vault
+	DefaultTimeout = 10 * time.Second
+)
+
+// defaultClient is used when Vault.Client is nil.
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// Vault reads the secret from a field of a key/value secret over the HTTP API of
+// HashiCorp Vault or a compatible server, e.g. OpenBao or a local dev server.
+// Both versions of the key/value engine are supported.
+//
+// The secret is cached for TTL. Only the first read waits for Vault; an expired secret
+// is refreshed in the background and the cached secret is returned meanwhile
+// and if the refresh fails. Err reports the error of the last read.
+type Vault struct {
+	// TTL is the time a secret is cached, DefaultTTL if not set
+	TTL time.Duration
+	// Client is the HTTP client, a client with DefaultTimeout if nil
+	Client *http.Client
+
+	address string
+	token   string
+	path    string
+	field   string
+
+	mu         sync.Mutex
+	value      string
+	fetchedAt  time.Time
+	refreshing bool
+	err        error
+}
+
+// NewVault creates a new Vault backend reading field of the secret at path, e.g.
+// `secret/data/webhooks/stripe` for the key/value engine v2 mounted at `secret`.
+// address is the base URL of the server, e.g. `http://127.0.0.1:8200`.
+func NewVault(address, token, path, field string) *Vault {
+	return &Vault{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		path:    strings.Trim(path, "/"),
+		field:   field,
+	}
+}
+
+// Secret returns the cached secret or reads it from Vault.
+func (v *Vault) Secret(ctx context.Context) (string, error) {
+	ttl := v.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	v.mu.Lock()
+	cached := v.value
+	if cached != "" {
+		if time.Since(v.fetchedAt) >= ttl && !v.refreshing {
+			v.refreshing = true
+			// the refresh must not be canceled with the request that triggered it
+			go v.refresh(context.WithoutCancel(ctx))
+		}
+		v.mu.Unlock()
+		return cached, nil
+	}
+	v.mu.Unlock()
+
+	// do not hold the lock across network I/O
+	value, err := v.fetch(ctx)
+
+	v.mu.Lock()
+	v.set(value, err)
+	v.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Err returns the error of the last read from Vault, nil if it succeeded.
+func (v *Vault) Err() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.err
+}
+
+// refresh reads the secret in the background.
+func (v *Vault) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	value, err := v.fetch(ctx)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.refreshing = false
+	v.set(value, err)
+}
+
+// set records the outcome of a read and keeps the cached secret if it failed.
+// It must be called with v.mu held.
+func (v *Vault) set(value string, err error) {
+	v.err = err
+	if err != nil {
+		return
+	}
+	v.value = value
+	v.fetchedAt = time.Now()
+}
+
+// fetch reads the field of the secret.
+func (v *Vault) fetch(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.address+"/v1/"+v.path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	client := v.Client
+	if client == nil {
+		client = defaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault %s: unexpected status: %s", v.path, res.Status)
+	}
+
+	// v1: {"data": {field: value}}, v2: {"data": {"data": {field: value}, "metadata": {...}}}
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err = json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("vault %s: %w", v.path, err)
+	}
+	fields := secret.Data
+	if nested, ok := fields["data"]; ok {
+		if _, isField := fields[v.field]; !isField {
+			if err = json.Unmarshal(nested, &fields); err != nil {
+				return "", fmt.Errorf("vault %s: %w", v.path, err)
+			}
+		}
+	}
+
+	raw, ok := fields[v.field]
+	if !ok {
+		return "", fmt.Errorf("vault %s: missing field %q", v.path, v.field)
+	}
+	var value string
+	if err = json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("vault %s: field %q is not a string", v.path, v.field)
+	}
+	if value = strings.TrimSpace(value); value == "" {
+		return "", fmt.Errorf("vault %s: empty secret", v.path)
+	}
+	return value, nil
+}
@@ -0,0 +1,131 @@
+package secret
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestVaultKeyValueVersions(t *testing.T) {
+	bodies := map[string]string{
+		"/v1/secret/data/webhooks": `{"data":{"data":{"resend":"v2-secret"},"metadata":{"version":3}}}`,
+		"/v1/kv/webhooks":          `{"data":{"resend":"v1-secret"}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	for path, want := range map[string]string{"secret/data/webhooks": "v2-secret", "kv/webhooks": "v1-secret"} {
+		got, err := NewVault(srv.URL, "token", path, "resend").Secret(context.Background())
+		if err != nil || got != want {
+			t.Errorf("%s: got %q, %v, want %q", path, got, err, want)
+		}
+	}
+
+	if _, err := NewVault(srv.URL, "wrong", "kv/webhooks", "resend").Secret(context.Background()); err == nil {
+		t.Error("got nil error for a wrong token")
+	}
+}
+
+func TestVaultRefreshesInBackground(t *testing.T) {
+	block := make(chan struct{})
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) > 1 {
+			<-block
+		}
+		_, _ = w.Write([]byte(`{"data":{"resend":"secret"}}`))
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	v := NewVault(srv.URL, "token", "kv/webhooks", "resend")
+	v.TTL = time.Millisecond
+	if _, err := v.Secret(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// neither the caller that triggers the refresh nor later callers wait for it
+	for range 3 {
+		done := make(chan string, 1)
+		go func() {
+			s, _ := v.Secret(context.Background())
+			done <- s
+		}()
+		select {
+		case s := <-done:
+			if s != "secret" {
+				t.Fatalf("got %q, want cached secret", s)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Secret blocked behind a refresh")
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := requests.Load(); n != 2 {
+		t.Errorf("got %d requests, want a single refresh in flight", n)
+	}
+}
+
+func TestVaultKeepsSecretWhenRefreshFails(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"resend":"secret"}}`))
+	}))
+	defer srv.Close()
+
+	v := NewVault(srv.URL, "token", "kv/webhooks", "resend")
+	v.TTL = time.Millisecond
+	if _, err := v.Secret(context.Background()); err != nil || v.Err() != nil {
+		t.Fatalf("got %v, %v", err, v.Err())
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if s, err := v.Secret(context.Background()); err != nil || s != "secret" {
+		t.Fatalf("got %q, %v, want cached secret", s, err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for v.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := v.Err(); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Err() = %v, want the failed refresh", err)
+	}
+	if s, err := v.Secret(context.Background()); err != nil || s != "secret" {
+		t.Errorf("got %q, %v after a failed refresh, want cached secret", s, err)
+	}
+}
+
+func TestVaultHonorsContextDeadline(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-block
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := NewVault(srv.URL, "token", "kv/webhooks", "resend").Secret(ctx); err == nil {
+		t.Fatal("got nil error from an unresponsive server")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Secret returned after %s", d)
+	}
+}
@@ -0,0 +1,145 @@
+// Package sink forwards normalized webhook events, e.g. to stdout,
+// a JSON Lines file or an HTTP endpoint.
+//
+// Delivery is at least once: when one sink of a Multi fails, the provider retries
+// the event and the sinks that already succeeded receive it again.
+// Consumers should deduplicate by provider and event ID.
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook"
+)
+
+// DefaultTimeout is the default timeout of a request of an HTTP sink.
+const DefaultTimeout = 10 * time.Second
+
+// Sink receives the normalized events.
+type Sink interface {
+	Write(ctx context.Context, event webhook.Event) error
+	Close() error
+}
+
+// Writer writes one JSON object per event and line, safe for concurrent use.
+type Writer struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewWriter creates a new sink writing to w, e.g. os.Stdout. Close does not close w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// NewFile creates a new sink appending to the JSON Lines file, created if it does not exist.
+func NewFile(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: f, closer: f}, nil
+}
+
+// Write writes the event as one line.
+func (s *Writer) Write(_ context.Context, event webhook.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(b)
+	return err
+}
+
+// Close closes the file of a file sink.
+func (s *Writer) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// HTTP posts every event as JSON to a URL and expects a 2xx response.
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP creates a new HTTP sink. The timeout is DefaultTimeout if not positive.
+func NewHTTP(url string, timeout time.Duration) *HTTP {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &HTTP{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Write posts the event.
+func (s *HTTP) Write(ctx context.Context, event webhook.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("sink %s: unexpected status: %s", s.url, res.Status)
+	}
+	return nil
+}
+
+// Close closes the idle connections.
+func (s *HTTP) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// Multi writes every event to all sinks.
+type Multi []Sink
+
+// Write writes the event to all sinks, also after a sink failed, and joins their errors.
+func (m Multi) Write(ctx context.Context, event webhook.Event) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Write(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes all sinks and joins their errors.
+func (m Multi) Close() error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,152 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook"
+)
+
+func testEvent(id string) webhook.Event {
+	return webhook.Event{
+		Provider: "stripe",
+		ID:       id,
+		Type:     "invoice.paid",
+		Created:  time.Date(2024, 11, 22, 10, 0, 0, 0, time.UTC),
+		Raw:      json.RawMessage(`{"id":"` + id + `"}`),
+	}
+}
+
+func TestFileAppends(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// a second sink on the same file, e.g. after a reload, appends to it
+	for _, id := range []string{"evt_1", "evt_2"} {
+		s, err := NewFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = s.Write(ctx, testEvent(id)); err != nil {
+			t.Fatal(err)
+		}
+		if err = s.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), b)
+	}
+	for i, id := range []string{"evt_1", "evt_2"} {
+		var got webhook.Event
+		if err = json.Unmarshal([]byte(lines[i]), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != id || got.Provider != "stripe" || string(got.Raw) != `{"id":"`+id+`"}` {
+			t.Errorf("line %d: got %+v", i+1, got)
+		}
+	}
+}
+
+func TestWriterDoesNotCloseWriter(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewWriter(&buf)
+	if err := s.Write(context.Background(), testEvent("evt_1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "}\n") || strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("got %q, want one line", buf.String())
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	status := http.StatusAccepted
+	var got webhook.Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	s := NewHTTP(srv.URL, 0)
+	defer s.Close()
+
+	if err := s.Write(context.Background(), testEvent("evt_1")); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "evt_1" || got.Type != "invoice.paid" {
+		t.Errorf("got %+v", got)
+	}
+
+	for _, status = range []int{http.StatusMovedPermanently, http.StatusBadRequest, http.StatusServiceUnavailable} {
+		err := s.Write(context.Background(), testEvent("evt_2"))
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("unexpected status: %d", status)) {
+			t.Errorf("status %d: got %v", status, err)
+		}
+	}
+}
+
+// testSink records written event IDs and fails with err.
+type testSink struct {
+	ids      []string
+	err      error
+	closeErr error
+}
+
+func (s *testSink) Write(_ context.Context, event webhook.Event) error {
+	s.ids = append(s.ids, event.ID)
+	return s.err
+}
+
+func (s *testSink) Close() error {
+	return s.closeErr
+}
+
+func TestMulti(t *testing.T) {
+	errFirst, errLast := errors.New("first failed"), errors.New("last failed")
+	first, ok, last := &testSink{err: errFirst, closeErr: errFirst}, &testSink{}, &testSink{err: errLast}
+	m := Multi{first, ok, last}
+
+	err := m.Write(context.Background(), testEvent("evt_1"))
+	if !errors.Is(err, errFirst) || !errors.Is(err, errLast) {
+		t.Errorf("got %v, want both errors", err)
+	}
+	// a failing sink does not stop the others
+	for i, s := range m {
+		if ids := s.(*testSink).ids; len(ids) != 1 || ids[0] != "evt_1" {
+			t.Errorf("sink %d: got %v", i, ids)
+		}
+	}
+
+	if err = m.Close(); !errors.Is(err, errFirst) || errors.Is(err, errLast) {
+		t.Errorf("Close() = %v, want only the close error", err)
+	}
+	if err = (Multi{ok}).Write(context.Background(), testEvent("evt_2")); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
@@ -0,0 +1,59 @@
+package standardwebhooks
+
+import (
+	"context"
+)
+
+// HandlerFunc processes a verified message.
+type HandlerFunc[T any] func(ctx context.Context, msg Message[T]) error
+
+// Middleware wraps the callback of every route, e.g. for logging or error handling.
+type Middleware[T any] func(next HandlerFunc[T]) HandlerFunc[T]
+
+// Router dispatches messages to the callback registered for their event type.
+type Router[T any] struct {
+	routes     map[string]HandlerFunc[T]
+	fallback   HandlerFunc[T]
+	middleware []Middleware[T]
+}
+
+// NewRouter creates a new router without routes.
+func NewRouter[T any]() *Router[T] {
+	return &Router[T]{routes: make(map[string]HandlerFunc[T])}
+}
+
+// On registers the callback for the given event type, replacing any previous one.
+func (r *Router[T]) On(eventType string, fn HandlerFunc[T]) *Router[T] {
+	r.routes[eventType] = fn
+	return r
+}
+
+// Fallback registers the callback for event types without a route.
+func (r *Router[T]) Fallback(fn HandlerFunc[T]) *Router[T] {
+	r.fallback = fn
+	return r
+}
+
+// Use adds middleware that wraps every callback, including the fallback.
+// Middleware runs in the order it was added.
+func (r *Router[T]) Use(mw ...Middleware[T]) *Router[T] {
+	r.middleware = append(r.middleware, mw...)
+	return r
+}
+
+// Dispatch passes the message to the callback registered for its event type,
+// or to the fallback. Messages without a callback are ignored.
+func (r *Router[T]) Dispatch(ctx context.Context, msg Message[T]) error {
+	fn, ok := r.routes[msg.Type]
+	if !ok {
+		fn = r.fallback
+	}
+	if fn == nil {
+		return nil
+	}
+
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		fn = r.middleware[i](fn)
+	}
+	return fn(ctx, msg)
+}
@@ -0,0 +1,66 @@
+package standardwebhooks
+
+import (
+	"net/http"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/svixgo"
+)
+
+// Verifier implements Verify and Forget of webhook.Provider and webhook.Forgetter
+// for Standard Webhooks, so that a provider only has to implement Parse.
+type Verifier struct {
+	wh      *svixgo.Webhook
+	secrets webhook.SecretProvider
+	opts    options
+}
+
+// NewVerifier creates a new verifier for the given webhook instance.
+func NewVerifier(wh *svixgo.Webhook, opts ...Option) *Verifier {
+	return &Verifier{
+		wh:   wh,
+		opts: newOptions(opts),
+	}
+}
+
+// NewVerifierWithSecret creates a new verifier that creates the webhook instance from the
+// signing secret read from sp for every request, so that the secret can rotate without a restart.
+// Use WithWebhookOptions to configure the webhook instance.
+func NewVerifierWithSecret(sp webhook.SecretProvider, opts ...Option) *Verifier {
+	return &Verifier{
+		secrets: sp,
+		opts:    newOptions(opts),
+	}
+}
+
+// Verify checks the request against the signature headers and returns the body.
+// A replayed message is rejected with a *webhook.Error of status 200 OK,
+// so that it is acknowledged without processing it again.
+func (v *Verifier) Verify(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	wh, err := v.webhook(r)
+	if err != nil {
+		return nil, err
+	}
+	return verify(w, r, wh, v.opts)
+}
+
+// Forget removes the message ID of the request from the nonce store of the webhook instance.
+func (v *Verifier) Forget(r *http.Request) error {
+	wh, err := v.webhook(r)
+	if err != nil {
+		return err
+	}
+	return wh.Forget(r.Context(), svixgo.MessageID(r.Header))
+}
+
+// webhook returns the webhook instance.
+func (v *Verifier) webhook(r *http.Request) (*svixgo.Webhook, error) {
+	if v.secrets == nil {
+		return v.wh, nil
+	}
+	wh, err := webhookFromSecret(r.Context(), v.secrets, v.opts)
+	if err != nil {
+		return nil, &webhook.Error{StatusCode: http.StatusInternalServerError, Err: err}
+	}
+	return wh, nil
+}
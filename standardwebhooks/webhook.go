@@ -0,0 +1,286 @@
+// Package standardwebhooks provides a generic consumer for providers that send
+// webhooks according to the Standard Webhooks specification, e.g. via svix.
+//
+// Adding a provider takes a model of its payload and a handler:
+//
+/*
+	type ClerkEvent struct {
+		Type   string          `json:"type"`
+		Object string          `json:"object"`
+		Data   json.RawMessage `json:"data"`
+	}
+
+	wh, err := svixgo.NewWebhook(secret)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	h := standardwebhooks.NewHandler[ClerkEvent](wh)
+	h.On("user.created", func(ctx context.Context, msg standardwebhooks.Message[ClerkEvent]) error {
+		log.Println(msg.ID, msg.Payload.Object)
+		return nil
+	})
+	http.Handle("/webhook/clerk", h)
+*/
+//
+// https://github.com/standard-webhooks/standard-webhooks/blob/main/spec/standard-webhooks.md
+package standardwebhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/svixgo"
+)
+
+// DefaultMaxBodyBytes is the default maximum size of a request body (64KB).
+const DefaultMaxBodyBytes = webhook.DefaultMaxBodyBytes
+
+// DefaultTypeField is the default JSON field holding the event type.
+const DefaultTypeField = "type"
+
+type options struct {
+	maxBodyBytes int64
+	typeField    []string
+	webhookOpts  []svixgo.Option
+}
+
+// Option configures HandleRequest and Handler.
+type Option func(*options)
+
+// WithMaxBodyBytes sets the maximum size of a request body.
+func WithMaxBodyBytes(n int64) Option {
+	return func(o *options) {
+		o.maxBodyBytes = n
+	}
+}
+
+// WithTypeField sets the JSON field holding the event type, DefaultTypeField if not set.
+// Nested fields are separated by dots, e.g. `data.event_type`.
+func WithTypeField(field string) Option {
+	return func(o *options) {
+		o.typeField = strings.Split(field, ".")
+	}
+}
+
+// WithWebhookOptions sets the options of the webhook instances created from a
+// webhook.SecretProvider, e.g. svixgo.WithNonceStore.
+func WithWebhookOptions(opts ...svixgo.Option) Option {
+	return func(o *options) {
+		o.webhookOpts = append(o.webhookOpts, opts...)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		maxBodyBytes: DefaultMaxBodyBytes,
+		typeField:    []string{DefaultTypeField},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Message is a verified message with its payload decoded into T.
+type Message[T any] struct {
+	// ID is the `webhook-id` header, unique per message and stable across retries
+	ID string
+	// Timestamp is the `webhook-timestamp` header of the delivery attempt
+	Timestamp time.Time
+	// Type is the value of the type field, empty if the payload has none
+	Type string
+	// Payload is the decoded body
+	Payload T
+	// Raw is the body as received
+	Raw []byte
+}
+
+// HandleRequest validates the incoming payload against the signature headers
+// and decodes it into a message.
+//
+// The returned status code is meant to be sent back to the provider when err is not nil.
+//
+// Replay protection is enabled by creating wh with svixgo.WithNonceStore.
+// A replayed message returns svixgo.ErrReplayed with 200 OK, so that the provider
+// stops retrying it without processing it again. The message ID is recorded before
+// the message is returned; if processing it fails, call wh.Forget(ctx, msg.ID)
+// before responding with an error, otherwise every retry is rejected as a replay.
+func HandleRequest[T any](w http.ResponseWriter, r *http.Request, wh *svixgo.Webhook, opts ...Option) (Message[T], int, error) {
+	return handleRequest[T](w, r, wh, newOptions(opts))
+}
+
+// HandleRequestWithSecret is HandleRequest with a webhook instance created from the
+// signing secret read from sp for every request, so that the secret can rotate without a restart.
+// Use WithWebhookOptions to configure the webhook instance.
+func HandleRequestWithSecret[T any](w http.ResponseWriter, r *http.Request, sp webhook.SecretProvider, opts ...Option) (Message[T], int, error) {
+	o := newOptions(opts)
+	wh, err := webhookFromSecret(r.Context(), sp, o)
+	if err != nil {
+		return Message[T]{}, http.StatusInternalServerError, err
+	}
+	return handleRequest[T](w, r, wh, o)
+}
+
+// webhookFromSecret creates a webhook instance with the current secret of sp.
+func webhookFromSecret(ctx context.Context, sp webhook.SecretProvider, o options) (*svixgo.Webhook, error) {
+	secret, err := sp.Secret(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("webhook secret: %w", err)
+	}
+	return svixgo.NewWebhook(secret, o.webhookOpts...)
+}
+
+// verify reads the body and validates it against the signature headers.
+// Errors are of type *webhook.Error; a replay has the status code 200 OK.
+func verify(w http.ResponseWriter, r *http.Request, wh *svixgo.Webhook, o options) ([]byte, error) {
+	body, err := webhook.ReadBody(w, r, o.maxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = wh.VerifyContext(r.Context(), body, r.Header); err != nil {
+		if errors.Is(err, svixgo.ErrReplayed) {
+			return nil, &webhook.Error{StatusCode: http.StatusOK, Err: err}
+		}
+		return nil, &webhook.Error{StatusCode: http.StatusBadRequest, Err: err}
+	}
+	return body, nil
+}
+
+func handleRequest[T any](w http.ResponseWriter, r *http.Request, wh *svixgo.Webhook, o options) (Message[T], int, error) {
+	body, err := verify(w, r, wh, o)
+	if err != nil {
+		return Message[T]{}, webhook.StatusCode(err), err
+	}
+
+	msg := Message[T]{
+		ID:  svixgo.MessageID(r.Header),
+		Raw: body,
+	}
+	if msg.Timestamp, err = svixgo.Timestamp(r.Header); err != nil {
+		return Message[T]{}, http.StatusBadRequest, err
+	}
+	if msg.Type, err = eventType(body, o.typeField); err != nil {
+		return Message[T]{}, http.StatusBadRequest, err
+	}
+	if err = json.Unmarshal(body, &msg.Payload); err != nil {
+		return Message[T]{}, http.StatusBadRequest, err
+	}
+
+	return msg, http.StatusOK, nil
+}
+
+// eventType returns the string at the given field path of the JSON body,
+// or an empty string if the field does not exist.
+func eventType(body []byte, path []string) (string, error) {
+	raw := json.RawMessage(body)
+	for _, field := range path {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return "", fmt.Errorf("read type field: %w", err)
+		}
+		var ok bool
+		if raw, ok = fields[field]; !ok {
+			return "", nil
+		}
+	}
+
+	if bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+	var t string
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return "", fmt.Errorf("read type field: %w", err)
+	}
+	return t, nil
+}
+
+// Handler is an http.Handler that verifies incoming requests with HandleRequest
+// and dispatches the message with its Router.
+//
+// Messages without a callback are acknowledged with 200 OK. If a callback
+// returns an error, 500 Internal Server Error is sent so that the provider retries the delivery.
+type Handler[T any] struct {
+	*Router[T]
+
+	wh      *svixgo.Webhook
+	secrets webhook.SecretProvider
+	opts    options
+	onError func(r *http.Request, err error)
+}
+
+// NewHandler creates a new handler for the given webhook instance with an empty router.
+func NewHandler[T any](wh *svixgo.Webhook, opts ...Option) *Handler[T] {
+	return &Handler[T]{
+		Router: NewRouter[T](),
+		wh:     wh,
+		opts:   newOptions(opts),
+	}
+}
+
+// NewHandlerWithSecret creates a new handler that creates the webhook instance from the
+// signing secret read from sp for every request, so that the secret can rotate without a restart.
+// Use WithWebhookOptions to configure the webhook instance.
+func NewHandlerWithSecret[T any](sp webhook.SecretProvider, opts ...Option) *Handler[T] {
+	return &Handler[T]{
+		Router:  NewRouter[T](),
+		secrets: sp,
+		opts:    newOptions(opts),
+	}
+}
+
+// OnError sets a function that is called with every rejected request
+// and every callback error, e.g. for logging.
+func (h *Handler[T]) OnError(fn func(r *http.Request, err error)) *Handler[T] {
+	h.onError = fn
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wh := h.wh
+	if h.secrets != nil {
+		var err error
+		if wh, err = webhookFromSecret(r.Context(), h.secrets, h.opts); err != nil {
+			h.error(r, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	msg, statusCode, err := handleRequest[T](w, r, wh, h.opts)
+	if err != nil {
+		// acknowledged replays are not errors
+		if statusCode >= 300 {
+			h.error(r, err)
+		}
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	if err = h.Dispatch(r.Context(), msg); err != nil {
+		h.error(r, fmt.Errorf("%s: %w", msg.Type, err))
+		// allow the provider to redeliver the message despite replay protection
+		if err = wh.Forget(r.Context(), msg.ID); err != nil {
+			h.error(r, err)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler[T]) error(r *http.Request, err error) {
+	if h.onError != nil {
+		h.onError(r, err)
+	}
+}
@@ -0,0 +1,139 @@
+package standardwebhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/svixgo"
+)
+
+const testSecret = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+
+type testEvent struct {
+	Type string `json:"type"`
+}
+
+func signedRequest(t *testing.T, msgID string, payload []byte) *http.Request {
+	t.Helper()
+	r, err := svixgo.NewSignedRequest(context.Background(), testSecret, "/webhook", msgID, time.Now(), payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestHandlerAcknowledgesReplays(t *testing.T) {
+	wh, err := svixgo.NewWebhook(testSecret, svixgo.WithNonceStore(svixgo.NewMemoryNonceStore(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	fail := true
+	var errs []error
+	h := NewHandler[testEvent](wh)
+	h.On("user.created", func(context.Context, Message[testEvent]) error {
+		calls++
+		if fail {
+			fail = false
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+	h.OnError(func(_ *http.Request, err error) { errs = append(errs, err) })
+
+	payload := []byte(`{"type":"user.created"}`)
+	for i, want := range []int{
+		// the failed message is forgotten, so that the retry is processed
+		http.StatusInternalServerError,
+		http.StatusOK,
+		// the replay is acknowledged without dispatch
+		http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, signedRequest(t, "msg_1", payload))
+		if w.Code != want {
+			t.Errorf("request %d: got status %d, want %d", i+1, w.Code, want)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("got %d calls, want 2", calls)
+	}
+	if len(errs) != 1 {
+		t.Errorf("got errors %v, want only the callback error", errs)
+	}
+}
+
+func TestHandleRequestReplay(t *testing.T) {
+	wh, err := svixgo.NewWebhook(testSecret, svixgo.WithNonceStore(svixgo.NewMemoryNonceStore(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"type":"user.created"}`)
+
+	msg, statusCode, err := HandleRequest[testEvent](httptest.NewRecorder(), signedRequest(t, "msg_1", payload), wh)
+	if err != nil || statusCode != http.StatusOK || msg.ID != "msg_1" || msg.Type != "user.created" {
+		t.Fatalf("got %+v, %d, %v", msg, statusCode, err)
+	}
+	if !bytes.Equal(msg.Raw, payload) {
+		t.Errorf("got raw %s, want %s", msg.Raw, payload)
+	}
+
+	_, statusCode, err = HandleRequest[testEvent](httptest.NewRecorder(), signedRequest(t, "msg_1", payload), wh)
+	if !errors.Is(err, svixgo.ErrReplayed) || statusCode != http.StatusOK {
+		t.Fatalf("got %d, %v, want %d, %v", statusCode, err, http.StatusOK, svixgo.ErrReplayed)
+	}
+}
+
+type failingSecret struct{}
+
+func (failingSecret) Secret(context.Context) (string, error) {
+	return "", errors.New("vault unavailable")
+}
+
+func TestVerifier(t *testing.T) {
+	store := svixgo.NewMemoryNonceStore(10)
+	v := NewVerifierWithSecret(webhook.StaticSecret(testSecret), WithMaxBodyBytes(64),
+		WithWebhookOptions(svixgo.WithNonceStore(store)))
+	payload := []byte(`{"type":"user.created"}`)
+
+	body, err := v.Verify(httptest.NewRecorder(), signedRequest(t, "msg_1", payload))
+	if err != nil || !bytes.Equal(body, payload) {
+		t.Fatalf("got %s, %v", body, err)
+	}
+
+	// the replay is acknowledged until the message ID is forgotten
+	r := signedRequest(t, "msg_1", payload)
+	if _, err = v.Verify(httptest.NewRecorder(), r); !errors.Is(err, svixgo.ErrReplayed) || webhook.StatusCode(err) != http.StatusOK {
+		t.Fatalf("got %v, want an acknowledged replay", err)
+	}
+	if err = v.Forget(r); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = v.Verify(httptest.NewRecorder(), signedRequest(t, "msg_1", payload)); err != nil {
+		t.Fatalf("got %v after Forget", err)
+	}
+
+	tests := []struct {
+		name string
+		v    *Verifier
+		r    *http.Request
+		want int
+	}{
+		{"unsigned", v, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload)), http.StatusBadRequest},
+		{"oversized", v, signedRequest(t, "msg_2", bytes.Repeat([]byte("x"), 100)), http.StatusRequestEntityTooLarge},
+		{"secret", NewVerifierWithSecret(failingSecret{}), signedRequest(t, "msg_3", payload), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if _, err = tt.v.Verify(httptest.NewRecorder(), tt.r); webhook.StatusCode(err) != tt.want {
+			t.Errorf("%s: got %v with status %d, want %d", tt.name, err, webhook.StatusCode(err), tt.want)
+		}
+	}
+}
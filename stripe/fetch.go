@@ -0,0 +1,174 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/stripe/stripe-go/v79"
+)
+
+// objectPaths maps the `object` field of an event's object to the API path
+// that retrieves its latest version.
+var objectPaths = map[string]string{
+	"charge":                "/v1/charges/%s",
+	"checkout.session":      "/v1/checkout/sessions/%s",
+	"coupon":                "/v1/coupons/%s",
+	"credit_note":           "/v1/credit_notes/%s",
+	"customer":              "/v1/customers/%s",
+	"dispute":               "/v1/disputes/%s",
+	"invoice":               "/v1/invoices/%s",
+	"invoiceitem":           "/v1/invoiceitems/%s",
+	"mandate":               "/v1/mandates/%s",
+	"payment_intent":        "/v1/payment_intents/%s",
+	"payment_link":          "/v1/payment_links/%s",
+	"payment_method":        "/v1/payment_methods/%s",
+	"plan":                  "/v1/plans/%s",
+	"price":                 "/v1/prices/%s",
+	"product":               "/v1/products/%s",
+	"promotion_code":        "/v1/promotion_codes/%s",
+	"quote":                 "/v1/quotes/%s",
+	"refund":                "/v1/refunds/%s",
+	"setup_intent":          "/v1/setup_intents/%s",
+	"source":                "/v1/sources/%s",
+	"subscription":          "/v1/subscriptions/%s",
+	"subscription_schedule": "/v1/subscription_schedules/%s",
+	"tax_id":                "/v1/tax_ids/%s",
+	"tax_rate":              "/v1/tax_rates/%s",
+}
+
+// singletonPaths maps objects that exist once per account to their API path.
+var singletonPaths = map[string]string{
+	"balance":      "/v1/balance",
+	"tax.settings": "/v1/tax/settings",
+}
+
+// NewBackend creates a Stripe API backend for the given base URL.
+// An empty URL selects the default Stripe API; any other URL,
+// e.g. a local stripe-mock or httptest server, overrides it.
+func NewBackend(url string) stripe.Backend {
+	if url == "" {
+		return stripe.GetBackend(stripe.APIBackend)
+	}
+	return stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		URL: stripe.String(url),
+	})
+}
+
+// Fetcher retrieves the latest version of the object referenced by an event.
+type Fetcher interface {
+	Fetch(ctx context.Context, event stripe.Event) (json.RawMessage, error)
+}
+
+// APIFetcher retrieves objects from the Stripe API.
+type APIFetcher struct {
+	B   stripe.Backend
+	Key string
+}
+
+// NewAPIFetcher creates a new fetcher using the secret API key.
+// See NewBackend for backendURL.
+func NewAPIFetcher(key, backendURL string) *APIFetcher {
+	return &APIFetcher{
+		B:   NewBackend(backendURL),
+		Key: key,
+	}
+}
+
+// rawResource keeps the raw API response of a retrieved object.
+type rawResource struct {
+	stripe.APIResource
+}
+
+// Fetch retrieves the current state of the event's object.
+// Events of connected accounts are retrieved on behalf of that account.
+func (f *APIFetcher) Fetch(ctx context.Context, event stripe.Event) (json.RawMessage, error) {
+	if event.Data == nil {
+		return nil, fmt.Errorf("event %s has no data", event.ID)
+	}
+
+	objectType, _ := event.Data.Object["object"].(string)
+	path, ok := singletonPaths[objectType]
+	if !ok {
+		format, ok := objectPaths[objectType]
+		if !ok {
+			return nil, fmt.Errorf("unsupported object type: %s", objectType)
+		}
+		id := ObjectID(event)
+		if id == "" {
+			return nil, fmt.Errorf("event %s has no object ID", event.ID)
+		}
+		path = stripe.FormatURLPath(format, id)
+	}
+
+	params := &stripe.Params{Context: ctx}
+	if event.Account != "" {
+		params.SetStripeAccount(event.Account)
+	}
+
+	res := &rawResource{}
+	err := f.B.Call(http.MethodGet, path, f.Key, params, res)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res.LastResponse.RawJSON), nil
+}
+
+// Refetcher replaces the object of selected events with its latest version
+// before the event is dispatched.
+//
+// https://docs.stripe.com/webhooks#event-retrieval
+type Refetcher struct {
+	fetcher Fetcher
+	types   map[stripe.EventType]bool
+}
+
+// NewRefetcher creates a new refetcher for the given event types.
+// If no types are given, every event is refetched.
+func NewRefetcher(fetcher Fetcher, types ...stripe.EventType) *Refetcher {
+	r := &Refetcher{fetcher: fetcher}
+	if len(types) > 0 {
+		r.types = make(map[stripe.EventType]bool, len(types))
+		for _, t := range types {
+			r.types[t] = true
+		}
+	}
+	return r
+}
+
+// Enabled reports whether events of the given type are refetched.
+// Deleted objects cannot be retrieved, so `*.deleted` events are never refetched.
+func (r *Refetcher) Enabled(eventType stripe.EventType) bool {
+	if strings.HasSuffix(string(eventType), ".deleted") {
+		return false
+	}
+	return r.types == nil || r.types[eventType]
+}
+
+// Refetch returns the event with event.Data.Raw and event.Data.Object
+// replaced by the latest version of the object. Events of types that
+// are not enabled are returned unchanged.
+func (r *Refetcher) Refetch(ctx context.Context, event stripe.Event) (stripe.Event, error) {
+	if !r.Enabled(event.Type) {
+		return event, nil
+	}
+
+	raw, err := r.fetcher.Fetch(ctx, event)
+	if err != nil {
+		return event, err
+	}
+
+	var object map[string]interface{}
+	if err = json.Unmarshal(raw, &object); err != nil {
+		return event, err
+	}
+
+	// copy the data so that the caller's event stays untouched
+	data := *event.Data
+	data.Raw = raw
+	data.Object = object
+	event.Data = &data
+	return event, nil
+}
@@ -0,0 +1,97 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pilinux/webhook/stripe/stripetest"
+	"github.com/stripe/stripe-go/v79"
+)
+
+func testEvent(t *testing.T, body string) stripe.Event {
+	t.Helper()
+	var e stripe.Event
+	if err := json.Unmarshal([]byte(body), &e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestAPIFetcherFetch(t *testing.T) {
+	srv := stripetest.NewServer()
+	defer srv.Close()
+	srv.SetObject("/v1/customers/cus_123", map[string]any{"id": "cus_123", "object": "customer", "email": "new@example.com"})
+	srv.SetObject("/v1/balance", map[string]any{"object": "balance", "livemode": false})
+
+	f := NewAPIFetcher("sk_test_123", srv.URL)
+	ctx := context.Background()
+
+	raw, err := f.Fetch(ctx, testEvent(t, `{"id":"evt_1","type":"customer.updated","account":"acct_1",
+		"data":{"object":{"id":"cus_123","object":"customer","email":"old@example.com"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var customer stripe.Customer
+	if err = json.Unmarshal(raw, &customer); err != nil {
+		t.Fatal(err)
+	}
+	if customer.Email != "new@example.com" {
+		t.Errorf("got email %q, want the latest version", customer.Email)
+	}
+
+	if _, err = f.Fetch(ctx, testEvent(t, `{"id":"evt_2","type":"balance.available","data":{"object":{"object":"balance"}}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 2 || reqs[0].Path != "/v1/customers/cus_123" || reqs[0].Account != "acct_1" || reqs[1].Path != "/v1/balance" {
+		t.Errorf("unexpected requests: %+v", reqs)
+	}
+
+	if _, err = f.Fetch(ctx, testEvent(t, `{"id":"evt_3","type":"customer.updated","data":{"object":{"id":"cus_404","object":"customer"}}}`)); err == nil {
+		t.Error("got nil error for a missing object")
+	}
+	if _, err = f.Fetch(ctx, testEvent(t, `{"id":"evt_4","type":"foo.updated","data":{"object":{"id":"foo_1","object":"foo"}}}`)); err == nil {
+		t.Error("got nil error for an unsupported object type")
+	}
+}
+
+func TestRefetcherRefetch(t *testing.T) {
+	srv := stripetest.NewServer()
+	defer srv.Close()
+	srv.SetObject("/v1/subscriptions/sub_1", map[string]any{"id": "sub_1", "object": "subscription", "status": "canceled"})
+
+	r := NewRefetcher(NewAPIFetcher("sk_test_123", srv.URL), stripe.EventTypeCustomerSubscriptionUpdated)
+	ctx := context.Background()
+
+	event := testEvent(t, `{"id":"evt_1","type":"customer.subscription.updated",
+		"data":{"object":{"id":"sub_1","object":"subscription","status":"active"}}}`)
+	got, err := r.Refetch(ctx, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Data.Object["status"] != "canceled" {
+		t.Errorf("got status %v, want the latest version", got.Data.Object["status"])
+	}
+	if event.Data.Object["status"] != "active" {
+		t.Error("the original event was modified")
+	}
+
+	// not enabled and deleted events are returned unchanged without a request
+	for _, body := range []string{
+		`{"id":"evt_2","type":"customer.subscription.created","data":{"object":{"id":"sub_1","object":"subscription","status":"active"}}}`,
+		`{"id":"evt_3","type":"customer.subscription.deleted","data":{"object":{"id":"sub_1","object":"subscription","status":"active"}}}`,
+	} {
+		got, err = r.Refetch(ctx, testEvent(t, body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Data.Object["status"] != "active" {
+			t.Errorf("%s was refetched", got.Type)
+		}
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
@@ -0,0 +1,53 @@
+package stripe
+
+import (
+	"github.com/stripe/stripe-go/v79"
+)
+
+// ObjectID returns the ID of the object contained in the event,
+// e.g. `sub_...` for `customer.subscription.updated`.
+func ObjectID(event stripe.Event) string {
+	if event.Data == nil {
+		return ""
+	}
+	return idValue(event.Data.Object["id"])
+}
+
+// CustomerID returns the ID of the customer the event belongs to.
+// For customer events it is the ID of the object itself.
+// An empty string is returned when the object does not reference a customer.
+func CustomerID(event stripe.Event) string {
+	if event.Data == nil {
+		return ""
+	}
+	if event.Data.Object["object"] == "customer" {
+		return ObjectID(event)
+	}
+	return idValue(event.Data.Object["customer"])
+}
+
+// SubscriptionID returns the ID of the subscription the event belongs to.
+// For subscription events it is the ID of the object itself.
+// An empty string is returned when the object does not reference a subscription.
+func SubscriptionID(event stripe.Event) string {
+	if event.Data == nil {
+		return ""
+	}
+	if event.Data.Object["object"] == "subscription" {
+		return ObjectID(event)
+	}
+	return idValue(event.Data.Object["subscription"])
+}
+
+// idValue returns the ID of a field that is either a plain ID
+// or an expanded object.
+func idValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		id, _ := v["id"].(string)
+		return id
+	}
+	return ""
+}
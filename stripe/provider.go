@@ -0,0 +1,76 @@
+package stripe
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pilinux/webhook"
+	"github.com/stripe/stripe-go/v79"
+	stripewebhook "github.com/stripe/stripe-go/v79/webhook"
+)
+
+// ProviderName is the name of the stripe provider in webhook.Event.
+const ProviderName = "stripe"
+
+// Provider implements webhook.Provider for stripe.
+// The typed object of its events is a stripe.Event.
+type Provider struct {
+	secrets webhook.SecretProvider
+
+	// MaxBodyBytes is the maximum size of a request body, webhook.DefaultMaxBodyBytes if not set.
+	MaxBodyBytes int64
+}
+
+var _ webhook.Provider = (*Provider)(nil)
+
+// NewProvider creates a new stripe provider with the webhook signing secret.
+func NewProvider(secret string) *Provider {
+	return NewProviderWithSecret(webhook.StaticSecret(secret))
+}
+
+// NewProviderWithSecret creates a new stripe provider that reads the webhook signing secret
+// from sp for every request, so that the secret can rotate without a restart.
+func NewProviderWithSecret(sp webhook.SecretProvider) *Provider {
+	return &Provider{secrets: sp}
+}
+
+// Verify checks the request against the Stripe-Signature header and returns the body.
+func (p *Provider) Verify(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body, err := webhook.ReadBody(w, r, p.MaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := p.secrets.Secret(r.Context())
+	if err != nil {
+		return nil, webhook.Errorf(http.StatusInternalServerError, "webhook secret: %w", err)
+	}
+
+	// also rejects events of a different API version, like HandleRequest
+	if _, err = stripewebhook.ConstructEvent(body, r.Header.Get("Stripe-Signature"), secret); err != nil {
+		return nil, &webhook.Error{StatusCode: http.StatusBadRequest, Err: err}
+	}
+	return body, nil
+}
+
+// Parse binds the verified body to an event.
+func (p *Provider) Parse(_ *http.Request, body []byte) (webhook.Event, error) {
+	var event stripe.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return webhook.Event{}, err
+	}
+	return NewEvent(event, body), nil
+}
+
+// NewEvent wraps a stripe event and its JSON in the common envelope.
+func NewEvent(event stripe.Event, raw []byte) webhook.Event {
+	return webhook.Event{
+		Provider: ProviderName,
+		ID:       event.ID,
+		Type:     string(event.Type),
+		Created:  time.Unix(event.Created, 0).UTC(),
+		Raw:      raw,
+		Object:   event,
+	}
+}
@@ -0,0 +1,150 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/dedup"
+	"github.com/stripe/stripe-go/v79"
+	"github.com/stripe/stripe-go/v79/event"
+)
+
+// Reconciler recovers events that were missed while the webhook endpoint was unavailable
+// for longer than Stripe's retry window.
+//
+// It periodically lists the events created since the last reconciled event through
+// the Events API and feeds them through dedup.Middleware to the same handler as the
+// webhook endpoint. The webhook endpoint must use dedup.Middleware with the same store,
+// so that an event delivered while a run is in progress is processed once.
+// The ttl of the store must exceed the time between a delivery and the run that
+// lists the event, otherwise the event is processed again.
+//
+// https://docs.stripe.com/webhooks/process-undelivered-events
+type Reconciler struct {
+	client  event.Client
+	seen    dedup.Store
+	handler webhook.HandlerFunc
+
+	mu    sync.Mutex
+	since int64
+}
+
+// NewReconciler creates a new reconciler using the secret API key.
+// See NewBackend for backendURL. Events are listed starting from now;
+// use SetSince to reconcile events from an earlier point.
+// The typed object of the events passed to handler is a stripe.Event.
+func NewReconciler(key, backendURL string, seen dedup.Store, handler webhook.HandlerFunc) *Reconciler {
+	return &Reconciler{
+		client: event.Client{
+			B:   NewBackend(backendURL),
+			Key: key,
+		},
+		seen:    seen,
+		handler: handler,
+		since:   time.Now().Unix(),
+	}
+}
+
+// SetSince sets the creation time from which events are listed.
+// Stripe keeps events for 30 days.
+func (r *Reconciler) SetSince(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.since = t.Unix()
+}
+
+// Since returns the creation time from which the next run lists events.
+func (r *Reconciler) Since() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return time.Unix(r.since, 0)
+}
+
+// Reconcile lists the events since the last reconciled event and dispatches
+// the ones not in the dedup store in the order they were created. It returns
+// the number of dispatched events.
+//
+// If the handler fails, reconciliation stops and the next run starts again
+// from the failed event.
+func (r *Reconciler) Reconcile(ctx context.Context) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	params := &stripe.EventListParams{
+		CreatedRange: &stripe.RangeQueryParams{
+			// events created within the same second may be listed again, they are deduplicated
+			GreaterThanOrEqual: r.since,
+		},
+	}
+	params.Context = ctx
+
+	var events []*stripe.Event
+	latest := r.since
+	iter := r.client.List(params)
+	for iter.Next() {
+		e := iter.Event()
+		if e.Created > latest {
+			latest = e.Created
+		}
+		events = append(events, e)
+	}
+	if err = iter.Err(); err != nil {
+		return
+	}
+
+	// the API lists the newest events first: reverse the list so that events
+	// created within the same second keep their creation order in the stable sort
+	slices.Reverse(events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Created < events[j].Created
+	})
+
+	// events already in the store are skipped by the middleware and not counted
+	handle := dedup.Middleware(r.seen)(func(ctx context.Context, event webhook.Event) error {
+		if err := r.handler(ctx, event); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	for _, e := range events {
+		raw, err := json.Marshal(e)
+		if err != nil {
+			r.since = e.Created
+			return n, fmt.Errorf("event %s: %w", e.ID, err)
+		}
+		if err = handle(ctx, NewEvent(*e, raw)); err != nil {
+			r.since = e.Created
+			return n, fmt.Errorf("event %s: %w", e.ID, err)
+		}
+	}
+
+	r.since = latest
+	return
+}
+
+// Run reconciles every interval until ctx is done.
+// Errors of a single run are passed to onError, which may be nil.
+func (r *Reconciler) Run(ctx context.Context, interval time.Duration, onError func(err error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := r.Reconcile(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package stripe
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pilinux/webhook"
+	"github.com/pilinux/webhook/dedup"
+	"github.com/pilinux/webhook/stripe/stripetest"
+	"github.com/stripe/stripe-go/v79"
+)
+
+func TestReconcilerReconcile(t *testing.T) {
+	srv := stripetest.NewServer()
+	defer srv.Close()
+
+	// in creation order; evt_2 and evt_3 are created within the same second
+	for _, e := range []struct {
+		id, typ string
+		created int64
+	}{
+		{"evt_1", "customer.created", 100},
+		{"evt_2", "customer.subscription.created", 200},
+		{"evt_3", "customer.subscription.updated", 200},
+		{"evt_4", "invoice.paid", 300},
+		{"evt_5", "invoice.paid", 400},
+	} {
+		srv.AddEvent(map[string]any{
+			"id": e.id, "object": "event", "type": e.typ, "created": e.created,
+			"data": map[string]any{"object": map[string]any{"id": "obj_" + e.id}},
+		})
+	}
+
+	ctx := context.Background()
+	seen := dedup.NewMemoryStore(time.Hour)
+	// already processed by the webhook endpoint
+	if _, err := seen.Add(ctx, dedup.Key(webhook.Event{Provider: ProviderName, ID: "evt_1"})); err != nil {
+		t.Fatal(err)
+	}
+
+	var dispatched []string
+	failed := false
+	r := NewReconciler("sk_test_123", srv.URL, seen, func(_ context.Context, e webhook.Event) error {
+		if _, ok := e.Object.(stripe.Event); !ok {
+			t.Errorf("got object %T, want stripe.Event", e.Object)
+		}
+		if e.ID == "evt_4" && !failed {
+			failed = true
+			return errors.New("temporary failure")
+		}
+		dispatched = append(dispatched, e.ID)
+		return nil
+	})
+	r.SetSince(time.Unix(100, 0))
+
+	// the first run skips the seen event, dispatches in creation order and stops at the failure
+	n, err := r.Reconcile(ctx)
+	if err == nil || n != 2 {
+		t.Fatalf("got %d, %v, want 2 and the handler error", n, err)
+	}
+	if want := []string{"evt_2", "evt_3"}; !reflect.DeepEqual(dispatched, want) {
+		t.Fatalf("dispatched %v, want %v", dispatched, want)
+	}
+	if got := r.Since(); got.Unix() != 300 {
+		t.Fatalf("since %d, want 300 (the failed event)", got.Unix())
+	}
+
+	// the next run resumes at the failed event
+	dispatched = nil
+	if n, err = r.Reconcile(ctx); err != nil || n != 2 {
+		t.Fatalf("got %d, %v, want 2", n, err)
+	}
+	if want := []string{"evt_4", "evt_5"}; !reflect.DeepEqual(dispatched, want) {
+		t.Fatalf("dispatched %v, want %v", dispatched, want)
+	}
+	if got := r.Since(); got.Unix() != 400 {
+		t.Fatalf("since %d, want 400", got.Unix())
+	}
+
+	// nothing is dispatched twice
+	dispatched = nil
+	if n, err = r.Reconcile(ctx); err != nil || n != 0 || len(dispatched) != 0 {
+		t.Fatalf("got %d, %v, %v, want nothing dispatched", n, err, dispatched)
+	}
+}
+
+func TestReconcilerSharesStoreWithWebhookHandler(t *testing.T) {
+	srv := stripetest.NewServer()
+	defer srv.Close()
+	srv.AddEvent(map[string]any{"id": "evt_1", "object": "event", "type": "invoice.paid", "created": 100,
+		"data": map[string]any{"object": map[string]any{"id": "in_1"}}})
+
+	ctx := context.Background()
+	var dispatched []string
+	handle := func(_ context.Context, e webhook.Event) error {
+		dispatched = append(dispatched, e.ID)
+		return nil
+	}
+
+	// a TTL shorter than the reconcile window forgets the delivery
+	for _, tt := range []struct {
+		ttl  time.Duration
+		want []string
+	}{
+		{time.Hour, []string{"evt_1"}},
+		{time.Millisecond, []string{"evt_1", "evt_1"}},
+	} {
+		store := dedup.NewMemoryStore(tt.ttl)
+		dispatched = nil
+
+		// delivered to the webhook endpoint
+		deliver := dedup.Middleware(store)(handle)
+		if err := deliver(ctx, webhook.Event{Provider: ProviderName, ID: "evt_1"}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		r := NewReconciler("sk_test_123", srv.URL, store, handle)
+		r.SetSince(time.Unix(100, 0))
+		if _, err := r.Reconcile(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dispatched, tt.want) {
+			t.Errorf("ttl %s: dispatched %v, want %v", tt.ttl, dispatched, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Package stripetest provides a local stand-in of the Stripe API for tests.
+//
+// Pass the URL of a Server as backend URL to stripe.NewAPIFetcher or stripe.NewReconciler:
+//
+/*
+	srv := stripetest.NewServer()
+	defer srv.Close()
+
+	srv.SetObject("/v1/customers/cus_123", map[string]any{"id": "cus_123", "object": "customer"})
+	fetcher := stripe.NewAPIFetcher("sk_test_123", srv.URL)
+*/
+package stripetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+)
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	// Account is the Stripe-Account header of requests on behalf of a connected account
+	Account string
+}
+
+// Server serves objects by path and lists events like `GET /v1/events`.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	objects  map[string]json.RawMessage
+	events   []json.RawMessage
+	requests []Request
+}
+
+// NewServer starts a new server without objects and events. Call Close when done.
+func NewServer() *Server {
+	s := &Server{objects: make(map[string]json.RawMessage)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// SetObject sets the object returned for GET requests to path, e.g. `/v1/customers/cus_123`.
+func (s *Server) SetObject(path string, object any) {
+	b, err := json.Marshal(object)
+	if err != nil {
+		panic(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[path] = b
+}
+
+// AddEvent adds an event in creation order. The event must have the fields `id` and `created`.
+func (s *Server) AddEvent(event any) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		panic(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, b)
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Account: r.Header.Get("Stripe-Account"),
+	})
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == http.MethodGet && r.URL.Path == "/v1/events" {
+		s.listEvents(w, r)
+		return
+	}
+
+	object, ok := s.objects[r.URL.Path]
+	if !ok || r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"type":"invalid_request_error","code":"resource_missing","message":"No such object"}}`))
+		return
+	}
+	_, _ = w.Write(object)
+}
+
+// listEvents lists the events newest first like the Events API,
+// supporting the `created[gte]`, `limit` and `starting_after` parameters.
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	gte, _ := strconv.ParseInt(q.Get("created[gte]"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 10
+	}
+	startingAfter := q.Get("starting_after")
+
+	list := struct {
+		Object  string            `json:"object"`
+		URL     string            `json:"url"`
+		HasMore bool              `json:"has_more"`
+		Data    []json.RawMessage `json:"data"`
+	}{Object: "list", URL: "/v1/events", Data: []json.RawMessage{}}
+
+	skipping := startingAfter != ""
+	for i := len(s.events) - 1; i >= 0; i-- {
+		var e struct {
+			ID      string `json:"id"`
+			Created int64  `json:"created"`
+		}
+		_ = json.Unmarshal(s.events[i], &e)
+
+		if skipping {
+			skipping = e.ID != startingAfter
+			continue
+		}
+		if e.Created < gte {
+			continue
+		}
+		if len(list.Data) == limit {
+			list.HasMore = true
+			break
+		}
+		list.Data = append(list.Data, s.events[i])
+	}
+
+	_ = json.NewEncoder(w).Encode(list)
+}
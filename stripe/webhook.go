@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 
+	pwebhook "github.com/pilinux/webhook"
 	"github.com/stripe/stripe-go/v79"
 	"github.com/stripe/stripe-go/v79/webhook"
 )
@@ -46,6 +47,16 @@ func HandleRequest(w http.ResponseWriter, r *http.Request, secret string) (strip
 	return event, http.StatusOK, nil
 }
 
+// HandleRequestWithSecret is HandleRequest with the webhook signing secret read from sp
+// for every request, so that the secret can rotate without a restart.
+func HandleRequestWithSecret(w http.ResponseWriter, r *http.Request, sp pwebhook.SecretProvider) (stripe.Event, int, error) {
+	secret, err := sp.Secret(r.Context())
+	if err != nil {
+		return stripe.Event{}, http.StatusInternalServerError, fmt.Errorf("webhook secret: %w", err)
+	}
+	return HandleRequest(w, r, secret)
+}
+
 // ProcessEventBalance processes the incoming event and binds the raw data to a stripe.Balance struct.
 /*
 - https://docs.stripe.com/api/balance/balance_object
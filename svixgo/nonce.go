@@ -0,0 +1,86 @@
+package svixgo
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore remembers verified message IDs for replay protection.
+type NonceStore interface {
+	// Add stores the message ID until expires. It reports false if the ID
+	// is already stored and has not expired yet.
+	Add(ctx context.Context, msgID string, expires time.Time) (added bool, err error)
+	// Remove deletes the message ID
+	Remove(ctx context.Context, msgID string) error
+}
+
+type nonce struct {
+	msgID   string
+	expires time.Time
+}
+
+// MemoryNonceStore is an in-memory NonceStore with a fixed capacity,
+// safe for concurrent use. When it is full, the least recently added
+// message ID is evicted, so the capacity must exceed the number of
+// messages expected within the timestamp tolerance.
+type MemoryNonceStore struct {
+	capacity int
+
+	mu    sync.Mutex
+	order *list.List
+	index map[string]*list.Element
+}
+
+// NewMemoryNonceStore creates a new in-memory nonce store holding at most capacity message IDs.
+func NewMemoryNonceStore(capacity int) *MemoryNonceStore {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &MemoryNonceStore{
+		capacity: capacity,
+		order:    list.New(),
+		index:    make(map[string]*list.Element),
+	}
+}
+
+// Add stores the message ID until expires.
+func (s *MemoryNonceStore) Add(_ context.Context, msgID string, expires time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := s.index[msgID]; ok {
+		if e.Value.(*nonce).expires.After(now) {
+			return false, nil
+		}
+		s.order.Remove(e)
+		delete(s.index, msgID)
+	}
+
+	// drop expired entries from the back, then the oldest entries if still full
+	for back := s.order.Back(); back != nil; back = s.order.Back() {
+		n := back.Value.(*nonce)
+		if n.expires.After(now) && s.order.Len() < s.capacity {
+			break
+		}
+		s.order.Remove(back)
+		delete(s.index, n.msgID)
+	}
+
+	s.index[msgID] = s.order.PushFront(&nonce{msgID: msgID, expires: expires})
+	return true, nil
+}
+
+// Remove deletes the message ID.
+func (s *MemoryNonceStore) Remove(_ context.Context, msgID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.index[msgID]; ok {
+		s.order.Remove(e)
+		delete(s.index, msgID)
+	}
+	return nil
+}
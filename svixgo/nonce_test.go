@@ -0,0 +1,115 @@
+package svixgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryNonceStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryNonceStore(2)
+	expires := time.Now().Add(time.Minute)
+
+	add := func(msgID string, want bool) {
+		t.Helper()
+		added, err := s.Add(ctx, msgID, expires)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if added != want {
+			t.Errorf("Add(%s) = %v, want %v", msgID, added, want)
+		}
+	}
+
+	add("msg_1", true)
+	add("msg_1", false)
+	add("msg_2", true)
+
+	// msg_1 is evicted when the store is full
+	add("msg_3", true)
+	add("msg_1", true)
+	add("msg_3", false)
+
+	if err := s.Remove(ctx, "msg_3"); err != nil {
+		t.Fatal(err)
+	}
+	add("msg_3", true)
+
+	// expired message IDs can be added again
+	if _, err := s.Add(ctx, "msg_4", time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	add("msg_4", true)
+}
+
+func TestVerifyReplay(t *testing.T) {
+	ctx := context.Background()
+	wh, err := NewWebhook(testSecret, testClock(0), WithNonceStore(NewMemoryNonceStore(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := testHeaders("webhook", testMsgID, testTimestamp, testSignature)
+
+	if err = wh.VerifyContext(ctx, testPayload, headers); err != nil {
+		t.Fatal(err)
+	}
+	if err = wh.VerifyContext(ctx, testPayload, headers); !errors.Is(err, ErrReplayed) {
+		t.Fatalf("got %v, want %v", err, ErrReplayed)
+	}
+
+	// a forgotten message is accepted again, e.g. after failed processing
+	if err = wh.Forget(ctx, testMsgID); err != nil {
+		t.Fatal(err)
+	}
+	if err = wh.VerifyContext(ctx, testPayload, headers); err != nil {
+		t.Fatal(err)
+	}
+
+	// forged requests do not block the message ID
+	if err = wh.Forget(ctx, testMsgID); err != nil {
+		t.Fatal(err)
+	}
+	forged := testHeaders("webhook", testMsgID, testTimestamp, "v1,dGVzdA==")
+	if err = wh.VerifyContext(ctx, testPayload, forged); !errors.Is(err, ErrNoMatchingSignature) {
+		t.Fatalf("got %v, want %v", err, ErrNoMatchingSignature)
+	}
+	if err = wh.VerifyContext(ctx, testPayload, headers); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyReplayAfterTolerance(t *testing.T) {
+	store := &recordingNonceStore{}
+	wh, err := NewWebhook(testSecret, testClock(time.Minute), WithNonceStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wh.Verify(testPayload, testHeaders("webhook", testMsgID, testTimestamp, testSignature)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the message ID is kept for the rest of the tolerance
+	remaining := time.Until(store.expires)
+	if remaining < DefaultTolerance-time.Minute-time.Second || remaining > DefaultTolerance-time.Minute {
+		t.Errorf("message ID expires in %s, want %s", remaining, DefaultTolerance-time.Minute)
+	}
+}
+
+type recordingNonceStore struct {
+	expires time.Time
+}
+
+func (s *recordingNonceStore) Add(_ context.Context, msgID string, expires time.Time) (bool, error) {
+	if msgID != testMsgID {
+		return false, fmt.Errorf("unexpected message ID %s", msgID)
+	}
+	s.expires = expires
+	return true, nil
+}
+
+func (s *recordingNonceStore) Remove(context.Context, string) error {
+	return nil
+}
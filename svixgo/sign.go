@@ -0,0 +1,101 @@
+package svixgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Sign returns the `webhook-signature` header value of the payload,
+// signed with the given symmetric secret, e.g. `whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw`,
+// or ed25519 private key with the prefix `whsk_`.
+func Sign(secret, msgID string, timestamp time.Time, payload []byte) (string, error) {
+	wh, err := NewWebhook(secret)
+	if err != nil {
+		return "", err
+	}
+	return wh.Sign(msgID, timestamp, payload)
+}
+
+// Sign returns the `webhook-signature` header value of the payload:
+// a `v1` signature for a symmetric secret or a `v1a` signature for a private key.
+// A webhook instance with only a public key cannot sign.
+func (wh *Webhook) Sign(msgID string, timestamp time.Time, payload []byte) (string, error) {
+	content := signedContent(msgID, strconv.FormatInt(timestamp.Unix(), 10), payload)
+
+	switch {
+	case wh.privateKey != nil:
+		return "v1a," + base64.StdEncoding.EncodeToString(ed25519.Sign(wh.privateKey, content)), nil
+	case wh.key != nil:
+		return "v1," + base64.StdEncoding.EncodeToString(wh.hmac(content)), nil
+	}
+	return "", errors.New("cannot sign with a public key")
+}
+
+// GenerateKey creates a new ed25519 key pair for `v1a` signatures
+// and returns it as `whpk_` and `whsk_` prefixed strings.
+func GenerateKey() (publicKey, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return
+	}
+	publicKey = publicKeyPrefix + base64.StdEncoding.EncodeToString(pub)
+	privateKey = privateKeyPrefix + base64.StdEncoding.EncodeToString(priv)
+	return
+}
+
+// PublicKey returns the `whpk_` prefixed public key of an asymmetric webhook instance,
+// or an empty string for a symmetric one.
+func (wh *Webhook) PublicKey() string {
+	if wh.publicKey == nil {
+		return ""
+	}
+	return publicKeyPrefix + base64.StdEncoding.EncodeToString(wh.publicKey)
+}
+
+// NewMessageID returns a new random message ID, e.g. `msg_5f0c8e0a9d6b4c2e8f1a3b7d9e2c4a6b`.
+func NewMessageID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "msg_" + hex.EncodeToString(b)
+}
+
+// NewSignedRequest creates a POST request to url carrying the JSON payload,
+// signed with the given secret or private key and the `webhook-id`, `webhook-timestamp`
+// and `webhook-signature` headers set.
+//
+// It can be sent with an http.Client to emit a webhook, or passed to
+// an http.Handler in tests:
+//
+/*
+	body := []byte(`{"type":"email.bounced","created_at":"2024-11-22T23:41:12.126Z","data":{}}`)
+	r, err := NewSignedRequest(ctx, secret, "/webhook", NewMessageID(), time.Now(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+*/
+func NewSignedRequest(ctx context.Context, secret, url, msgID string, timestamp time.Time, payload []byte) (*http.Request, error) {
+	sig, err := Sign(secret, msgID, timestamp, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(standardHeaders.id, msgID)
+	r.Header.Set(standardHeaders.timestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	r.Header.Set(standardHeaders.signature, sig)
+	return r, nil
+}
@@ -0,0 +1,134 @@
+package svixgo
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsymmetricSignAndVerify(t *testing.T) {
+	publicKey, privateKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(publicKey, publicKeyPrefix) || !strings.HasPrefix(privateKey, privateKeyPrefix) {
+		t.Fatalf("unexpected key prefixes: %s, %s", publicKey[:5], privateKey[:5])
+	}
+
+	signer, err := NewWebhook(privateKey, testClock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.PublicKey() != publicKey {
+		t.Error("the public key of the private key does not match")
+	}
+	sig, err := signer.Sign(testMsgID, time.Unix(1614265330, 0), testPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sig, "v1a,") {
+		t.Fatalf("got %s, want a v1a signature", sig)
+	}
+
+	verifier, err := NewWebhook(publicKey, testClock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := testHeaders("webhook", testMsgID, testTimestamp, sig)
+	if err = verifier.Verify(testPayload, headers); err != nil {
+		t.Error(err)
+	}
+	if err = signer.Verify(testPayload, headers); err != nil {
+		t.Error(err)
+	}
+
+	// a v1 and a v1a signature in the same header
+	headers = testHeaders("webhook", testMsgID, testTimestamp, testSignature+" "+sig)
+	if err = verifier.Verify(testPayload, headers); err != nil {
+		t.Error(err)
+	}
+	symmetric, err := NewWebhook(testSecret, testClock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = symmetric.Verify(testPayload, headers); err != nil {
+		t.Error(err)
+	}
+
+	// a v1a signature is not accepted by a symmetric secret and vice versa
+	if err = symmetric.Verify(testPayload, testHeaders("webhook", testMsgID, testTimestamp, sig)); !errors.Is(err, ErrNoMatchingSignature) {
+		t.Errorf("got %v, want %v", err, ErrNoMatchingSignature)
+	}
+	if err = verifier.Verify(testPayload, testHeaders("webhook", testMsgID, testTimestamp, testSignature)); !errors.Is(err, ErrNoMatchingSignature) {
+		t.Errorf("got %v, want %v", err, ErrNoMatchingSignature)
+	}
+
+	// another key or a modified payload is rejected
+	otherPublicKey, _, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewWebhook(otherPublicKey, testClock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = other.Verify(testPayload, testHeaders("webhook", testMsgID, testTimestamp, sig)); !errors.Is(err, ErrNoMatchingSignature) {
+		t.Errorf("got %v, want %v", err, ErrNoMatchingSignature)
+	}
+	if err = verifier.Verify([]byte(`{}`), testHeaders("webhook", testMsgID, testTimestamp, sig)); !errors.Is(err, ErrNoMatchingSignature) {
+		t.Errorf("got %v, want %v", err, ErrNoMatchingSignature)
+	}
+
+	// a public key cannot sign
+	if _, err = verifier.Sign(testMsgID, time.Now(), testPayload); err == nil {
+		t.Error("got nil error signing with a public key")
+	}
+}
+
+func TestNewWebhookPrivateKeySeed(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	fromSeed, err := NewWebhook(privateKeyPrefix + base64.StdEncoding.EncodeToString(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromKey, err := NewWebhookPrivateKey(ed25519.NewKeyFromSeed(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromSeed.PublicKey() != fromKey.PublicKey() {
+		t.Error("seed and private key give different public keys")
+	}
+
+	if _, err = NewWebhookPrivateKey(seed[:10]); err == nil {
+		t.Error("got nil error for an invalid key size")
+	}
+	if _, err = NewWebhook(publicKeyPrefix + base64.StdEncoding.EncodeToString(seed[:10])); err == nil {
+		t.Error("got nil error for an invalid public key size")
+	}
+}
+
+func TestNewSignedRequest(t *testing.T) {
+	r, err := NewSignedRequest(context.Background(), testSecret, "/webhook", testMsgID, time.Now(), testPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = strconv.ParseInt(r.Header.Get("webhook-timestamp"), 10, 64); err != nil {
+		t.Fatal(err)
+	}
+
+	wh, err := NewWebhook(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wh.Verify(testPayload, r.Header); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,52 @@
+package svixgo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// SQLiteNonceStore stores verified message IDs in a SQLite table named webhook_nonces,
+// so that replay protection works across restarts and processes sharing the database.
+type SQLiteNonceStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteNonceStore creates a new SQLite nonce store and the webhook_nonces table if it does not exist.
+func NewSQLiteNonceStore(ctx context.Context, db *sql.DB) (*SQLiteNonceStore, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS webhook_nonces (
+		msg_id TEXT PRIMARY KEY,
+		expires_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteNonceStore{db: db}, nil
+}
+
+// Add stores the message ID until expires. Expired message IDs are removed along the way.
+func (s *SQLiteNonceStore) Add(ctx context.Context, msgID string, expires time.Time) (bool, error) {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM webhook_nonces WHERE expires_at <= ?`, time.Now().UnixNano())
+	if err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO webhook_nonces (msg_id, expires_at) VALUES (?, ?)`,
+		msgID, expires.UnixNano(),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Remove deletes the message ID.
+func (s *SQLiteNonceStore) Remove(ctx context.Context, msgID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM webhook_nonces WHERE msg_id = ?`, msgID)
+	return err
+}
@@ -1,18 +1,189 @@
-// Package svixgo is a wrapper around the github.com/svix/svix-webhooks/go package.
+// Package svixgo verifies webhooks signed according to the Standard Webhooks specification,
+// as sent by svix and every provider built on it.
+//
+// https://github.com/standard-webhooks/standard-webhooks/blob/main/spec/standard-webhooks.md
 package svixgo
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prefixes of base64 encoded keys
+const (
+	// secretPrefix is the prefix of symmetric signing secrets
+	secretPrefix = "whsec_"
+	// publicKeyPrefix is the prefix of ed25519 public keys
+	publicKeyPrefix = "whpk_"
+	// privateKeyPrefix is the prefix of ed25519 private keys
+	privateKeyPrefix = "whsk_"
+)
+
+// DefaultTolerance is the default maximum allowed difference between the message timestamp and the current time.
+const DefaultTolerance = 5 * time.Minute
+
+// errors returned by Verify
+var (
+	ErrRequiredHeaders     = errors.New("missing required headers")
+	ErrInvalidHeaders      = errors.New("invalid signature headers")
+	ErrMessageTooOld       = errors.New("message timestamp too old")
+	ErrMessageTooNew       = errors.New("message timestamp too new")
+	ErrNoMatchingSignature = errors.New("no matching signature found")
+	ErrReplayed            = errors.New("message already verified")
+)
 
-	svix "github.com/svix/svix-webhooks/go"
+// headerNames are the names of the id, timestamp and signature headers.
+type headerNames struct {
+	id        string
+	timestamp string
+	signature string
+}
+
+var (
+	standardHeaders = headerNames{
+		id:        "webhook-id",
+		timestamp: "webhook-timestamp",
+		signature: "webhook-signature",
+	}
+	svixHeaders = headerNames{
+		id:        "svix-id",
+		timestamp: "svix-timestamp",
+		signature: "svix-signature",
+	}
 )
 
-// NewWebhook creates a new webhook instance with the given secret.
-func NewWebhook(secret string) (*svix.Webhook, error) {
-	return svix.NewWebhook(secret)
+// Webhook verifies messages with a symmetric signing secret (`v1` signatures)
+// or an ed25519 public key (`v1a` signatures).
+type Webhook struct {
+	key        []byte
+	publicKey  ed25519.PublicKey
+	privateKey ed25519.PrivateKey
+
+	tolerance time.Duration
+	now       func() time.Time
+	nonces    NonceStore
+}
+
+// Option configures a webhook instance.
+type Option func(*Webhook)
+
+// WithNonceStore enables replay protection: a message ID is accepted only once
+// within the timestamp tolerance, later verifications of it fail with ErrReplayed.
+func WithNonceStore(store NonceStore) Option {
+	return func(wh *Webhook) {
+		wh.nonces = store
+	}
+}
+
+// WithTolerance sets the maximum allowed difference between the message timestamp
+// and the current time, DefaultTolerance if not set.
+func WithTolerance(d time.Duration) Option {
+	return func(wh *Webhook) {
+		if d > 0 {
+			wh.tolerance = d
+		}
+	}
+}
+
+// WithClock sets the function returning the current time, time.Now if not set.
+// It is meant for tests.
+func WithClock(now func() time.Time) Option {
+	return func(wh *Webhook) {
+		if now != nil {
+			wh.now = now
+		}
+	}
+}
+
+func (wh *Webhook) apply(opts []Option) *Webhook {
+	wh.tolerance = DefaultTolerance
+	wh.now = time.Now
+	for _, opt := range opts {
+		opt(wh)
+	}
+	return wh
+}
+
+// NewWebhook creates a new webhook instance with the given secret or key:
+//
+//   - a symmetric secret, e.g. `whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw`, verifies and signs `v1` signatures
+//   - an ed25519 public key with the prefix `whpk_` verifies `v1a` signatures
+//   - an ed25519 private key with the prefix `whsk_` verifies and signs `v1a` signatures
+func NewWebhook(secret string, opts ...Option) (*Webhook, error) {
+	switch {
+	case strings.HasPrefix(secret, publicKeyPrefix):
+		key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, publicKeyPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		return NewWebhookPublicKey(key, opts...)
+
+	case strings.HasPrefix(secret, privateKeyPrefix):
+		key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, privateKeyPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		return NewWebhookPrivateKey(key, opts...)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, secretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook secret: %w", err)
+	}
+	return NewWebhookRaw(key, opts...)
 }
 
-// Verify validates the incoming payload against the svix signature headers using the webhooks signing secret.
+// NewWebhookPublicKey creates a new webhook instance that verifies `v1a` signatures
+// with the decoded ed25519 public key.
+func NewWebhookPublicKey(key []byte, opts ...Option) (*Webhook, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: %d", len(key))
+	}
+	wh := &Webhook{publicKey: ed25519.PublicKey(key)}
+	return wh.apply(opts), nil
+}
+
+// NewWebhookPrivateKey creates a new webhook instance that signs and verifies `v1a` signatures
+// with the decoded ed25519 private key, given either as 64-byte key or as 32-byte seed.
+func NewWebhookPrivateKey(key []byte, opts ...Option) (*Webhook, error) {
+	var privateKey ed25519.PrivateKey
+	switch len(key) {
+	case ed25519.PrivateKeySize:
+		privateKey = ed25519.PrivateKey(key)
+	case ed25519.SeedSize:
+		privateKey = ed25519.NewKeyFromSeed(key)
+	default:
+		return nil, fmt.Errorf("invalid private key size: %d", len(key))
+	}
+	wh := &Webhook{
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+		privateKey: privateKey,
+	}
+	return wh.apply(opts), nil
+}
+
+// NewWebhookRaw creates a new webhook instance with the decoded secret.
+func NewWebhookRaw(key []byte, opts ...Option) (*Webhook, error) {
+	if len(key) == 0 {
+		return nil, errors.New("empty webhook secret")
+	}
+	wh := &Webhook{key: key}
+	return wh.apply(opts), nil
+}
+
+// Verify validates the incoming payload against the signature headers using the webhooks signing secret.
+//
+// Both the `webhook-*` headers of the Standard Webhooks specification
+// and the `svix-*` aliases are accepted.
 //
 /*
 	wh, err := NewWebhook(secret)
@@ -41,6 +212,175 @@ func NewWebhook(secret string) (*svix.Webhook, error) {
 	})
 	http.ListenAndServe(":8080", nil)
 */
-func Verify(wh *svix.Webhook, payload []byte, headers http.Header) error {
+func Verify(wh *Webhook, payload []byte, headers http.Header) error {
 	return wh.Verify(payload, headers)
 }
+
+// Verify validates the payload against the signature headers.
+func (wh *Webhook) Verify(payload []byte, headers http.Header) error {
+	return wh.VerifyContext(context.Background(), payload, headers)
+}
+
+// VerifyContext validates the payload against the signature headers.
+// ctx is passed to the nonce store.
+func (wh *Webhook) VerifyContext(ctx context.Context, payload []byte, headers http.Header) error {
+	msgID, timestamp, signatures, err := parseHeaders(headers)
+	if err != nil {
+		return err
+	}
+
+	now := wh.now()
+	sec, err := verifyTimestamp(timestamp, now, wh.tolerance)
+	if err != nil {
+		return err
+	}
+
+	if err = wh.verifySignatures(msgID, timestamp, payload, signatures); err != nil {
+		return err
+	}
+
+	// remember the message only after it is authenticated,
+	// so that forged requests cannot block genuine message IDs
+	if wh.nonces != nil {
+		// the message is acceptable until its timestamp plus the tolerance on the
+		// verifier's clock, the store measures expiry on the wall clock
+		remaining := time.Unix(sec, 0).Add(wh.tolerance).Sub(now)
+		added, err := wh.nonces.Add(ctx, msgID, time.Now().Add(remaining))
+		if err != nil {
+			return err
+		}
+		if !added {
+			return ErrReplayed
+		}
+	}
+	return nil
+}
+
+// VerifyIgnoringTimestamp validates the payload against the signature headers
+// without checking the timestamp or the nonce store, e.g. to re-process archived payloads.
+func (wh *Webhook) VerifyIgnoringTimestamp(payload []byte, headers http.Header) error {
+	msgID, timestamp, signatures, err := parseHeaders(headers)
+	if err != nil {
+		return err
+	}
+	if _, err = strconv.ParseInt(timestamp, 10, 64); err != nil {
+		return ErrInvalidHeaders
+	}
+	return wh.verifySignatures(msgID, timestamp, payload, signatures)
+}
+
+// Forget removes the message ID from the nonce store, so that the provider can
+// redeliver a message whose processing failed after verification.
+func (wh *Webhook) Forget(ctx context.Context, msgID string) error {
+	if wh.nonces == nil || msgID == "" {
+		return nil
+	}
+	return wh.nonces.Remove(ctx, msgID)
+}
+
+// verifySignatures checks that at least one of the signatures is valid.
+func (wh *Webhook) verifySignatures(msgID, timestamp string, payload []byte, signatures []string) error {
+	content := signedContent(msgID, timestamp, payload)
+
+	// the header may contain signatures of several versions and keys, one match is enough
+	for _, sig := range signatures {
+		version, value, ok := strings.Cut(sig, ",")
+		if !ok {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+
+		switch version {
+		case "v1":
+			if wh.key != nil && hmac.Equal(decoded, wh.hmac(content)) {
+				return nil
+			}
+		case "v1a":
+			if wh.publicKey != nil && ed25519.Verify(wh.publicKey, content, decoded) {
+				return nil
+			}
+		}
+	}
+	return ErrNoMatchingSignature
+}
+
+// signedContent returns `msgID.timestamp.payload`.
+func signedContent(msgID, timestamp string, payload []byte) []byte {
+	content := make([]byte, 0, len(msgID)+len(timestamp)+len(payload)+2)
+	content = append(content, msgID...)
+	content = append(content, '.')
+	content = append(content, timestamp...)
+	content = append(content, '.')
+	content = append(content, payload...)
+	return content
+}
+
+// hmac returns the HMAC-SHA256 of the signed content.
+func (wh *Webhook) hmac(content []byte) []byte {
+	mac := hmac.New(sha256.New, wh.key)
+	mac.Write(content)
+	return mac.Sum(nil)
+}
+
+// parseHeaders returns the message ID, the timestamp and the space separated
+// signatures from the `webhook-*` headers or their `svix-*` aliases.
+func parseHeaders(headers http.Header) (msgID, timestamp string, signatures []string, err error) {
+	names := standardHeaders
+	if headers.Get(names.id) == "" {
+		names = svixHeaders
+	}
+
+	msgID = headers.Get(names.id)
+	timestamp = headers.Get(names.timestamp)
+	signature := headers.Get(names.signature)
+	if msgID == "" || timestamp == "" || signature == "" {
+		err = ErrRequiredHeaders
+		return
+	}
+
+	signatures = strings.Fields(signature)
+	return
+}
+
+// MessageID returns the message ID from the `webhook-id` header or its `svix-id` alias.
+func MessageID(headers http.Header) string {
+	if id := headers.Get(standardHeaders.id); id != "" {
+		return id
+	}
+	return headers.Get(svixHeaders.id)
+}
+
+// Timestamp returns the message timestamp from the `webhook-timestamp` header
+// or its `svix-timestamp` alias.
+func Timestamp(headers http.Header) (time.Time, error) {
+	timestamp := headers.Get(standardHeaders.timestamp)
+	if headers.Get(standardHeaders.id) == "" {
+		timestamp = headers.Get(svixHeaders.timestamp)
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidHeaders
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// verifyTimestamp checks that the unix timestamp is within the tolerance around now
+// and returns it.
+func verifyTimestamp(timestamp string, now time.Time, tolerance time.Duration) (int64, error) {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidHeaders
+	}
+
+	diff := now.Unix() - sec
+	if diff > int64(tolerance/time.Second) {
+		return 0, ErrMessageTooOld
+	}
+	if -diff > int64(tolerance/time.Second) {
+		return 0, ErrMessageTooNew
+	}
+	return sec, nil
+}
@@ -0,0 +1,146 @@
+package svixgo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// test vector published by svix
+const (
+	testSecret    = "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+	testMsgID     = "msg_p5jXN8AQM9LWM0D4loKWxJek"
+	testTimestamp = "1614265330"
+	testSignature = "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="
+)
+
+var testPayload = []byte(`{"test": 2432232314}`)
+
+// testClock returns the time of the test vector plus d.
+func testClock(d time.Duration) Option {
+	return WithClock(func() time.Time {
+		return time.Unix(1614265330, 0).Add(d)
+	})
+}
+
+func testHeaders(prefix, msgID, timestamp, signature string) http.Header {
+	h := http.Header{}
+	h.Set(prefix+"-id", msgID)
+	h.Set(prefix+"-timestamp", timestamp)
+	h.Set(prefix+"-signature", signature)
+	return h
+}
+
+func TestVerifyPublishedVector(t *testing.T) {
+	wh, err := NewWebhook(testSecret, testClock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, prefix := range []string{"webhook", "svix"} {
+		if err = wh.Verify(testPayload, testHeaders(prefix, testMsgID, testTimestamp, testSignature)); err != nil {
+			t.Errorf("%s headers: %v", prefix, err)
+		}
+	}
+
+	// the secret works without the whsec_ prefix, too
+	wh, err = NewWebhook(testSecret[len(secretPrefix):], testClock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wh.Verify(testPayload, testHeaders("webhook", testMsgID, testTimestamp, testSignature)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	wh, err := NewWebhook(testSecret, testClock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		headers http.Header
+		want    error
+	}{
+		{"missing headers", testPayload, http.Header{}, ErrRequiredHeaders},
+		{"missing signature", testPayload, testHeaders("webhook", testMsgID, testTimestamp, ""), ErrRequiredHeaders},
+		{"invalid timestamp", testPayload, testHeaders("webhook", testMsgID, "yesterday", testSignature), ErrInvalidHeaders},
+		{"modified payload", []byte(`{"test": 2432232315}`), testHeaders("webhook", testMsgID, testTimestamp, testSignature), ErrNoMatchingSignature},
+		{"other message ID", testPayload, testHeaders("webhook", "msg_other", testTimestamp, testSignature), ErrNoMatchingSignature},
+		{"unknown version", testPayload, testHeaders("webhook", testMsgID, testTimestamp, "v2,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="), ErrNoMatchingSignature},
+		{"malformed signature", testPayload, testHeaders("webhook", testMsgID, testTimestamp, "g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="), ErrNoMatchingSignature},
+	}
+	for _, tt := range tests {
+		if err := wh.Verify(tt.payload, tt.headers); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyMultipleSignatures(t *testing.T) {
+	wh, err := NewWebhook(testSecret, testClock(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// e.g. during secret rotation, one valid signature is enough
+	signatures := "v1,Ceo5qEr07ixe2NLpvHk3FH9bwy/WavXrAFQ/9tdO6mc= " + testSignature + " v1a,invalid"
+	if err = wh.Verify(testPayload, testHeaders("webhook", testMsgID, testTimestamp, signatures)); err != nil {
+		t.Error(err)
+	}
+
+	signatures = "v1,Ceo5qEr07ixe2NLpvHk3FH9bwy/WavXrAFQ/9tdO6mc= v1,dGVzdA=="
+	if err = wh.Verify(testPayload, testHeaders("webhook", testMsgID, testTimestamp, signatures)); !errors.Is(err, ErrNoMatchingSignature) {
+		t.Errorf("got %v, want %v", err, ErrNoMatchingSignature)
+	}
+}
+
+func TestVerifyTolerance(t *testing.T) {
+	headers := testHeaders("webhook", testMsgID, testTimestamp, testSignature)
+
+	tests := []struct {
+		name      string
+		offset    time.Duration
+		tolerance time.Duration
+		want      error
+	}{
+		{"default tolerance, old", DefaultTolerance, 0, nil},
+		{"default tolerance, too old", DefaultTolerance + time.Second, 0, ErrMessageTooOld},
+		{"default tolerance, new", -DefaultTolerance, 0, nil},
+		{"default tolerance, too new", -DefaultTolerance - time.Second, 0, ErrMessageTooNew},
+		{"custom tolerance, old", 30 * time.Second, 30 * time.Second, nil},
+		{"custom tolerance, too old", 31 * time.Second, 30 * time.Second, ErrMessageTooOld},
+	}
+	for _, tt := range tests {
+		wh, err := NewWebhook(testSecret, testClock(tt.offset), WithTolerance(tt.tolerance))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = wh.Verify(testPayload, headers); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// archived payloads verify without the timestamp check
+	wh, err := NewWebhook(testSecret, testClock(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wh.VerifyIgnoringTimestamp(testPayload, headers); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSignMatchesPublishedVector(t *testing.T) {
+	sig, err := Sign(testSecret, testMsgID, time.Unix(1614265330, 0), testPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig != testSignature {
+		t.Errorf("got %s, want %s", sig, testSignature)
+	}
+}
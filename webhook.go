@@ -0,0 +1,144 @@
+// Package webhook defines a provider independent abstraction over incoming webhooks,
+// so that middleware, storage and dispatch are written once for all providers.
+//
+// The provider packages implement Provider, e.g. stripe.NewProvider and resend.NewProvider:
+//
+/*
+	wh, err := svixgo.NewWebhook(resendSecret)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store := func(ctx context.Context, event webhook.Event) error {
+		log.Println(event.Provider, event.ID, event.Type, event.Created)
+		return nil
+	}
+
+	http.Handle("/webhook/stripe", webhook.NewHandler(stripe.NewProvider(stripeSecret), store))
+	http.Handle("/webhook/resend", webhook.NewHandler(resend.NewProvider(wh), store))
+*/
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultMaxBodyBytes is the default maximum size of a request body (64KB).
+const DefaultMaxBodyBytes = int64(65536)
+
+// Event is the common envelope of a webhook event.
+type Event struct {
+	// Provider is the name of the provider, e.g. `stripe` or `resend`
+	Provider string `json:"provider"`
+	// ID identifies the event and is stable across redeliveries
+	ID string `json:"id"`
+	// Type is the provider specific event type, e.g. `invoice.paid` or `email.bounced`
+	Type string `json:"type"`
+	// Created is the time the event occurred at the provider
+	Created time.Time `json:"created"`
+	// Raw is the verified request body
+	Raw json.RawMessage `json:"raw"`
+	// Object is the typed event of the provider package, e.g. stripe.Event or resend.Payload
+	Object any `json:"-"`
+}
+
+// Provider verifies and parses the requests of a webhook provider.
+type Provider interface {
+	// Verify checks the request against its signature and returns the body.
+	// Errors should be of type *Error to carry the status code sent to the provider;
+	// an error with a 2xx status code acknowledges the request without processing it.
+	Verify(w http.ResponseWriter, r *http.Request) ([]byte, error)
+	// Parse binds the verified body of the request to an event.
+	Parse(r *http.Request, body []byte) (Event, error)
+}
+
+// Forgetter is implemented by providers with replay protection, so that a request
+// whose processing failed is not rejected as a replay when the provider redelivers it.
+type Forgetter interface {
+	Forget(r *http.Request) error
+}
+
+// SecretProvider returns the current webhook signing secret, so that secrets can
+// rotate without a restart. Implementations are in package secret.
+type SecretProvider interface {
+	Secret(ctx context.Context) (string, error)
+}
+
+// StaticSecret is a SecretProvider of a fixed secret.
+type StaticSecret string
+
+// Secret returns the secret.
+func (s StaticSecret) Secret(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// Error is a request error with the status code to send back to the provider.
+type Error struct {
+	StatusCode int
+	Err        error
+}
+
+// Errorf creates a new request error with the given status code.
+func Errorf(statusCode int, format string, a ...any) *Error {
+	return &Error{StatusCode: statusCode, Err: fmt.Errorf(format, a...)}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the status code of a request error,
+// 400 Bad Request for any other non-nil error and 200 OK for nil.
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var reqErr *Error
+	if errors.As(err, &reqErr) {
+		return reqErr.StatusCode
+	}
+	return http.StatusBadRequest
+}
+
+// ReadBody checks that the request is a POST request and reads the body,
+// limited to maxBodyBytes or DefaultMaxBodyBytes if not positive.
+// Like http.MaxBytesReader, it tells the server to close the connection
+// of w after an oversized body.
+func ReadBody(w http.ResponseWriter, r *http.Request, maxBodyBytes int64) ([]byte, error) {
+	// webhook events are always POST requests
+	if r.Method != http.MethodPost {
+		return nil, Errorf(http.StatusMethodNotAllowed, "invalid request method: %s", r.Method)
+	}
+
+	// limit the request body size to prevent DoS attacks
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, &Error{StatusCode: http.StatusRequestEntityTooLarge, Err: err}
+		}
+		return nil, &Error{StatusCode: http.StatusBadRequest, Err: err}
+	}
+	return body, nil
+}
+
+// HandlerFunc processes a verified event.
+type HandlerFunc func(ctx context.Context, event Event) error
+
+// Middleware wraps a HandlerFunc, e.g. for logging, deduplication or storage.
+type Middleware func(next HandlerFunc) HandlerFunc
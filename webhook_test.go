@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ReadBody(w, r, 8); err != nil {
+			w.WriteHeader(StatusCode(err))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+		close  bool
+	}{
+		{"valid", http.MethodPost, "12345678", http.StatusOK, false},
+		{"method", http.MethodGet, "", http.StatusMethodNotAllowed, false},
+		// the server closes the connection instead of reading the rest of the body
+		{"too large", http.MethodPost, strings.Repeat("x", 100), http.StatusRequestEntityTooLarge, true},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, srv.URL, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.want || resp.Close != tt.close {
+			t.Errorf("%s: got status %d and close %v, want %d and %v", tt.name, resp.StatusCode, resp.Close, tt.want, tt.close)
+		}
+	}
+}